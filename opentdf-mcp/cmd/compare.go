@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opentdf/opentdf-mcp/internal/access"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleCompareEntitlements compares the entitlements of two or more
+// entities: the attribute values they share and those that set each apart.
+// Given TDFs, directories or -r attribute sets, it also lists the documents
+// each entity can open that another cannot.
+//
+// Usage:
+//   compare-entitlements -e <[type:]id> -e <[type:]id> [-e ...] [-hierarchy] [-json] [<tdf|dir>...]
+//   compare-entitlements -e <[type:]id> -e <[type:]id> -r <name>=<fqn>[,<fqn>]... [-r ...]
+//
+// Entities are client IDs unless prefixed with "email:" or "username:". A
+// nanoTDF whose policy is encrypted cannot be read and is refused; give its
+// attributes with -r instead.
+func handleCompareEntitlements() error {
+	fs := flag.NewFlagSet("compare-entitlements", flag.ExitOnError)
+	var entityArgs, resourceArgs []string
+	fs.Func("e", "Entity as [clientId|email|username:]id (specify two or more)", func(s string) error {
+		entityArgs = append(entityArgs, s)
+		return nil
+	})
+	fs.Func("r", "Document attribute set as NAME=FQN[,FQN...] (can be specified multiple times)", func(s string) error {
+		resourceArgs = append(resourceArgs, s)
+		return nil
+	})
+	hierarchy := fs.Bool("hierarchy", false, "Also compare the values below an entitled value of a hierarchy attribute")
+	asJSON := fs.Bool("json", false, "Print the comparison as JSON")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	entities, err := access.ParseEntities(entityArgs)
+	if err != nil {
+		return err
+	}
+	if len(entities) < 2 {
+		return fmt.Errorf("at least two -e entities are required")
+	}
+	documents, err := access.FileResources(fs.Args())
+	if err != nil {
+		return err
+	}
+	for _, s := range resourceArgs {
+		res, err := access.ParseResource(s)
+		if err != nil {
+			return err
+		}
+		documents = append(documents, res)
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	clientID := getClientID()
+	clientSecret := getClientSecret()
+
+	// Create authenticated client
+	var opts []sdk.Option
+	if clientID != "" && clientSecret != "" {
+		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		opts = append(opts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create SDK client: %w", err)
+	}
+	defer client.Close()
+
+	comparison, err := access.Compare(context.Background(), client, entities, documents, *hierarchy)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal comparison: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Print(comparison.Summary())
+	return nil
+}
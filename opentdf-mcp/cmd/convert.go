@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleConvert re-encapsulates a TDF in the other container format,
+// keeping its attribute policy and KAS.
+//
+// Usage:
+//   convert -f nano|ztdf [-a <fqn>]... [-allow-unverified] [-allow-plaintext-policy] [-policy <mode>] [-binding <type>] [-o <output>] <input>
+//
+// Without -a the source policy is copied. With -a the list must include
+// every source attribute; a conversion that would weaken the policy is
+// refused. A nanoTDF with an encrypted policy cannot be checked, so it is
+// only converted with -a and -allow-unverified. Such a source is converted to
+// ZTDF, whose manifest stores the policy unencrypted, only with
+// -allow-plaintext-policy. The output defaults to the input path with the
+// target's extension. Plaintext is never written to disk.
+func handleConvert() error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	format := fs.String("f", "", "Target format: nano or ztdf")
+	output := fs.String("o", "", "Output file path (default: input with the target extension)")
+	policyMode := fs.String("policy", "", "nanoTDF target policy mode: encrypted or plaintext (default: encrypted)")
+	binding := fs.String("binding", "", "nanoTDF target policy binding: ecdsa or gmac (default: ecdsa)")
+	allowUnverified := fs.Bool("allow-unverified", false, "Accept -a for a source whose nanoTDF policy is encrypted and cannot be checked")
+	allowPlaintextPolicy := fs.Bool("allow-plaintext-policy", false, "Accept a ZTDF target that stores the encrypted policy of a source nanoTDF unencrypted")
+
+	var opts tdf.ConvertOptions
+	fs.Func("a", "Attribute for the target policy (can be specified multiple times)", func(s string) error {
+		opts.Attributes = append(opts.Attributes, s)
+		return nil
+	})
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("input file is required")
+	}
+	inputFile := fs.Arg(0)
+	if *format == "" {
+		return fmt.Errorf("-f target format is required")
+	}
+
+	opts.AllowUnverified = *allowUnverified
+	opts.AllowPlaintextPolicy = *allowPlaintextPolicy
+
+	var err error
+	if opts.Target, err = tdf.ParseFormat(*format); err != nil {
+		return err
+	}
+	if opts.PolicyMode, err = tdf.ParsePolicyMode(*policyMode); err != nil {
+		return err
+	}
+	if opts.Binding, err = tdf.ParseBinding(*binding); err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + opts.Target.Extension()
+	}
+	if *output == inputFile {
+		return fmt.Errorf("output would overwrite the input; specify -o")
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	clientID := getClientID()
+	clientSecret := getClientSecret()
+	opts.KasURL = tdf.KasURL(platformEndpoint)
+
+	// Create authenticated client
+	var sdkOpts []sdk.Option
+	if clientID != "" && clientSecret != "" {
+		sdkOpts = append(sdkOpts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		sdkOpts = append(sdkOpts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, sdkOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create SDK client: %w", err)
+	}
+	defer client.Close()
+
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	// Write next to the destination and rename, so a failed conversion
+	// never leaves a partial TDF behind
+	tmp, err := os.CreateTemp(filepath.Dir(*output), ".tmp-"+filepath.Base(*output)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	result, err := tdf.Convert(context.Background(), client, tmp, file, opts)
+	if errors.Is(err, tdf.ErrUnverifiedPolicy) {
+		err = fmt.Errorf("%w; pass -allow-unverified only if the -a attributes are the complete policy it was encrypted with", err)
+	}
+	if errors.Is(err, tdf.ErrExposedPolicy) {
+		err = fmt.Errorf("%w; pass -allow-plaintext-policy only if its attribute values may be disclosed", err)
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set output permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), *output); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("Converted %s (%s) to %s (%s, %d bytes)\n", inputFile, result.Source, *output, result.Target, result.EncryptedSize)
+	for _, a := range result.TargetAttributes {
+		fmt.Printf("  %s\n", a)
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	return nil
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/opentdf/opentdf-mcp/internal/doctdf"
+	"github.com/opentdf/opentdf-mcp/internal/memotdf"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
 	"github.com/opentdf/platform/sdk"
 )
 
 func handleDecrypt() error {
 	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	output := fs.String("o", "", "Output file path (default: stdout); written with owner-only permissions")
+	verbose := fs.Bool("v", false, "Report the container format and nanoTDF policy mode on stderr")
 
 	if err := fs.Parse(os.Args[2:]); err != nil {
 		return fmt.Errorf("failed to parse flags: %w", err)
@@ -23,13 +28,18 @@ func handleDecrypt() error {
 	}
 
 	inputFile := fs.Arg(0)
+	if err := tdf.CheckOutput(inputFile, *output); err != nil {
+		return err
+	}
 
 	platformEndpoint := getPlatformEndpoint()
 	clientID := getClientID()
 	clientSecret := getClientSecret()
 
-	// Create authenticated client
-	var opts []sdk.Option
+	// Create authenticated client. Sealed values in a JSON or YAML document
+	// that share a policy share a nanoTDF collection header, so storing
+	// collection keys saves a KAS rewrap per value.
+	opts := []sdk.Option{sdk.WithStoreCollectionHeaders()}
 	if clientID != "" && clientSecret != "" {
 		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
 	} else {
@@ -49,42 +59,120 @@ func handleDecrypt() error {
 	}
 	defer file.Close()
 
-	// Detect format by reading magic bytes
-	var magic [3]byte
-	var isNano bool
-	n, err := io.ReadFull(file, magic[:])
-	switch {
-	case err != nil:
-		return fmt.Errorf("failed to read magic bytes: %w", err)
-	case n < 3:
-		return fmt.Errorf("file too small; no magic number found")
-	case bytes.HasPrefix(magic[:], []byte("L1L")):
-		isNano = true
-	default:
-		isNano = false
-	}
-
-	// Reset file position
-	if _, err := file.Seek(0, 0); err != nil {
-		return fmt.Errorf("failed to seek to beginning: %w", err)
-	}
-
-	if isNano {
-		// Decrypt nanoTDF
-		if _, err := client.ReadNanoTDF(os.Stdout, file); err != nil {
-			return fmt.Errorf("failed to decrypt nanoTDF: %w", err)
+	// Detect the container format; anything that is not a readable TDF is
+	// rejected with a description of what it appears to be
+	format, err := tdf.DetectFormat(file)
+	var unsupported *tdf.UnsupportedFormatError
+	if errors.As(err, &unsupported) && unsupported.Detected == tdf.DetectedPlaintext {
+		// A memo with encrypted portions, or a JSON or YAML document with
+		// field-level encryption, is plaintext apart from its sealed values.
+		// Memo frontmatter parses as YAML, so memos are checked first.
+		data, readErr := io.ReadAll(file)
+		if readErr != nil {
+			return fmt.Errorf("failed to read input file: %w", readErr)
 		}
-	} else {
-		// Decrypt standard TDF
-		tdfReader, err := client.LoadTDF(file)
+		if memotdf.IsSealedMemo(data) {
+			return decryptMemo(client, data, *output)
+		}
+		if doctdf.IsSealedDocument(data) {
+			return decryptDocument(client, data, inputFile, *output)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	description := format.String()
+	if format == tdf.FormatNano {
+		policy, err := tdf.ReadNanoPolicy(file)
 		if err != nil {
-			return fmt.Errorf("failed to load TDF: %w", err)
+			return err
 		}
+		description = fmt.Sprintf("%s (%s)", tdf.FormatNano, policy)
+	}
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "Decrypting %s\n", description)
+	}
 
-		if _, err := io.Copy(os.Stdout, tdfReader); err != nil && !errors.Is(err, io.EOF) {
-			return fmt.Errorf("failed to decrypt TDF: %w", err)
+	// Write plaintext to stdout unless an output file was requested. The
+	// file is only put in place once decryption has succeeded, so a denial
+	// leaves whatever was at the path untouched.
+	var out io.Writer = os.Stdout
+	var outFile *tdf.OutputFile
+	if *output != "" {
+		if outFile, err = tdf.CreateOutput(*output, 0o600); err != nil {
+			return err
 		}
+		defer outFile.Discard()
+		out = outFile
+	}
+
+	if err := tdf.Decrypt(context.Background(), client, out, file, format); err != nil {
+		return err
+	}
+
+	if outFile != nil {
+		if err := outFile.Commit(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Successfully decrypted %s to: %s\n", description, *output)
 	}
 
 	return nil
 }
+
+// decryptDocument opens the sealed values of a JSON or YAML document and
+// writes it to output, or stdout. Values the caller may not open are shown
+// as [REDACTED].
+func decryptDocument(client *sdk.SDK, data []byte, name, output string) error {
+	syntax := doctdf.DetectSyntax(name, data)
+	doc, result, err := doctdf.Decrypt(context.Background(), tdf.NewOpener(client), data, syntax)
+	if err != nil {
+		return err
+	}
+
+	if err := writePlaintext(doc, output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Decrypted %s document: %d fields opened, %d redacted\n", syntax, len(result.Opened), len(result.Redacted))
+	for _, p := range result.Redacted {
+		fmt.Fprintf(os.Stderr, "  redacted %s\n", p)
+	}
+	return nil
+}
+
+// decryptMemo opens the sealed portions of a memo and writes it to output,
+// or stdout. Portions the caller may not open keep their marking and are
+// shown as [REDACTED], so the rest of the memo can still be read.
+func decryptMemo(client *sdk.SDK, data []byte, output string) error {
+	doc, result, err := memotdf.Decrypt(context.Background(), tdf.NewOpener(client), data)
+	if err != nil {
+		return err
+	}
+	if err := writePlaintext(doc, output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Decrypted memo: %d portions opened, %d redacted\n", len(result.Opened), len(result.Redacted))
+	for _, p := range result.Redacted {
+		marking := "unmarked"
+		if p.Marking != "" {
+			marking = "(" + p.Marking + ")"
+		}
+		fmt.Fprintf(os.Stderr, "  redacted %s portion at line %d\n", marking, p.Line)
+	}
+	return nil
+}
+
+// writePlaintext writes decrypted data to output with owner-only
+// permissions, or to stdout when output is empty.
+func writePlaintext(data []byte, output string) error {
+	if output == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+	return tdf.WriteOutput(output, data, 0o600)
+}
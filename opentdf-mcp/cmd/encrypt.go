@@ -1,39 +1,103 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/opentdf/opentdf-mcp/internal/access"
+	"github.com/opentdf/opentdf-mcp/internal/agent"
+	"github.com/opentdf/opentdf-mcp/internal/doctdf"
+	"github.com/opentdf/opentdf-mcp/internal/memotdf"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
 	"github.com/opentdf/platform/sdk"
 )
 
-// handleEncrypt processes the encrypt command to create a nanoTDF encrypted file.
-// It accepts plaintext data and optional attributes to encrypt the data using OpenTDF.
+// handleEncrypt processes the encrypt command to create a nanoTDF or ZTDF encrypted file.
+// It accepts plaintext data (a command-line argument, a file, or stdin) and optional
+// attributes to encrypt the data using OpenTDF.
 //
 // Usage:
 //   encrypt [flags] <plaintext>
+//   encrypt [flags] -i <file|->
+//   encrypt -field <jsonpath>=<fqn>[,<fqn>]... [-a <fqn>]... -i <file.json|file.yaml>
+//   encrypt -memo [-markings <table.yaml>] -i <memo.md>
+//   encrypt -memo-source <memo.md> [-markings <table.yaml>] -i <memo.md|memo.pdf>
+//   encrypt -override-markings -a <fqn> -i <file>
+//   encrypt -no-verify -a <fqn> -i <file>
 //
 // Flags:
+//   -i string
+//       Input file to encrypt; "-" reads stdin. Mutually exclusive with <plaintext>
 //   -o string
-//       Output file path (default "encrypted.tdf")
+//       Output file path (default "encrypted.ntdf", or "encrypted.tdf" for ZTDF)
+//   -f string
+//       Container format: "nano" (default) or "ztdf"
+//   -m string
+//       MIME type recorded in the ZTDF manifest (default: sniffed from the data)
+//   -policy string
+//       nanoTDF policy mode: "encrypted" (default) or "plaintext"
+//   -binding string
+//       nanoTDF policy binding: "ecdsa" (default) or "gmac"
 //   -a string
 //       Data attribute FQN (can be specified multiple times)
 //       Example: -a https://example.com/attr/attr1/value/value1
+//   -field string
+//       JSONPath selector and its attributes, e.g. '$.crew[*].name=<fqn>'
+//       (can be specified multiple times). Only the selected values of the
+//       JSON or YAML input are encrypted, as inline nanoTDF strings; -a
+//       attributes apply to every selected value
+//   -fields string
+//       YAML or JSON file listing {path, attributes} selectors
+//   -memo
+//       Encrypt each portion-marked paragraph of memo markdown separately,
+//       under the attributes of its marking; (U) portions and the
+//       frontmatter stay readable
+//   -memo-source string
+//       Memo markdown whose frontmatter sets the attributes: the
+//       classification banner, flight identifiers and functional tags are
+//       mapped to FQNs. The input must be that markdown or a PDF rendered
+//       from it; -a attributes, if any, must agree with the frontmatter
+//   -markings string
+//       YAML or JSON table mapping portion markings and frontmatter values to
+//       attributes (default: the demo.usaf.mil classification, flight_id and
+//       functional attributes); its classifications also drive the marking
+//       guard
+//   -override-markings
+//       Encrypt even though the plaintext carries classification banners or
+//       portion markings above what the attributes protect; the override is
+//       logged to stderr with the client ID
+//   -no-verify
+//       Skip confirming that every attribute FQN names an active value on
+//       the platform (for offline use); FQNs are still normalized and parsed
 //
 // The function:
 //  1. Parses command-line flags and plaintext input
 //  2. Retrieves platform endpoint and authentication credentials from environment
 //  3. Creates an authenticated OpenTDF SDK client
-//  4. Configures nanoTDF with attributes and ECDSA policy binding
-//  5. Encrypts the plaintext data and writes it to the output file
+//  4. Normalizes the attribute FQNs and confirms they exist and are active,
+//     suggesting the closest defined values for unknown ones, and warns if
+//     the caller itself could not decrypt the output
+//  5. Refuses plaintext whose classification markings call for attributes
+//     that were not given, unless -override-markings is set
+//  6. Configures the selected TDF format with attributes and the platform KAS
+//  7. Streams the plaintext into the encrypted output file and reports the sizes
 //
 // Returns an error if any step fails, including flag parsing, client creation,
 // attribute configuration, or encryption operations.
 func handleEncrypt() error {
 	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
-	output := fs.String("o", "encrypted.ntdf", "Output file path")
+	input := fs.String("i", "", "Input file path (\"-\" for stdin)")
+	output := fs.String("o", "", "Output file path (default \"encrypted.ntdf\" or \"encrypted.tdf\")")
+	formatName := fs.String("f", "nano", "Container format: nano or ztdf")
+	mimeType := fs.String("m", "", "MIME type recorded in the ZTDF manifest")
+	policyModeName := fs.String("policy", "", "nanoTDF policy mode: encrypted (default) or plaintext")
+	bindingName := fs.String("binding", "", "nanoTDF policy binding: ecdsa (default) or gmac")
 
 	// Parse attributes flag multiple times
 	var attributes []string
@@ -42,15 +106,117 @@ func handleEncrypt() error {
 		return nil
 	})
 
+	// Field-level encryption of JSON and YAML documents
+	var fields []doctdf.Field
+	fs.Func("field", "JSONPath selector and attributes, PATH=FQN[,FQN...] (can be specified multiple times)", func(s string) error {
+		f, err := doctdf.ParseField(s)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+		return nil
+	})
+	fieldsFile := fs.String("fields", "", "YAML or JSON file listing JSONPath selectors and their attributes")
+
+	// Portion-by-portion encryption of memo markdown
+	memo := fs.Bool("memo", false, "Encrypt the portion-marked paragraphs of memo markdown under the attributes of their markings")
+	memoSource := fs.String("memo-source", "", "Memo markdown whose frontmatter sets the attributes of the input (the memo or its rendered PDF)")
+	markingsFile := fs.String("markings", "", "YAML or JSON table mapping portion markings and frontmatter values to attributes")
+	noVerify := fs.Bool("no-verify", false, "Do not check the attributes against the platform (FQNs are still parsed)")
+	overrideMarkings := fs.Bool("override-markings", false, "Encrypt even if the plaintext is marked above what the attributes protect (logged)")
+
 	if err := fs.Parse(os.Args[2:]); err != nil {
 		return fmt.Errorf("failed to parse flags: %w", err)
 	}
 
-	if fs.NArg() < 1 {
-		return fmt.Errorf("plaintext data is required")
+	if fs.NArg() < 1 && *input == "" {
+		return fmt.Errorf("plaintext data or -i input file is required")
+	}
+	if fs.NArg() > 0 && *input != "" {
+		return fmt.Errorf("cannot specify both plaintext data and -i input file")
+	}
+
+	format, err := tdf.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	if *fieldsFile != "" {
+		loaded, err := doctdf.LoadFields(*fieldsFile)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, loaded...)
+	}
+	if len(fields) > 0 && format == tdf.FormatZTDF {
+		return fmt.Errorf("field-level encryption embeds nanoTDF values; -f ztdf does not apply")
+	}
+	if *memo {
+		if len(fields) > 0 || len(attributes) > 0 || format == tdf.FormatZTDF {
+			return fmt.Errorf("-memo takes its attributes from the portion markings; -a, -field and -f ztdf do not apply")
+		}
+		if *input == "" {
+			return fmt.Errorf("-memo requires the memo markdown as -i input")
+		}
+	}
+	table := memotdf.DefaultTable()
+	if *markingsFile != "" {
+		if table, err = memotdf.LoadTable(*markingsFile); err != nil {
+			return err
+		}
+	}
+
+	// The attributes of a memo, or a PDF rendered from it, come from its
+	// frontmatter; explicit attributes may only restate them
+	if *memoSource != "" {
+		if *memo || len(fields) > 0 {
+			return fmt.Errorf("-memo-source encrypts the whole input; -memo and -field do not apply")
+		}
+		if *input == "" || *input == "-" {
+			return fmt.Errorf("-memo-source requires the memo or its rendered PDF as -i input")
+		}
+		if err := memotdf.CheckRendition(*input, *memoSource); err != nil {
+			return err
+		}
+		derivation, err := memotdf.DeriveFile(*memoSource, table)
+		if err != nil {
+			return err
+		}
+		if err := derivation.Check(attributes); err != nil {
+			return err
+		}
+		attributes = derivation.Attributes
+		fmt.Printf("Attributes from the %s frontmatter (classification %q):\n", *memoSource, derivation.Classification)
+		for _, a := range attributes {
+			fmt.Printf("  %s\n", a)
+		}
+		if len(attributes) == 0 {
+			fmt.Println("  (none: the memo is unclassified and references no flights or tags)")
+		}
 	}
 
-	plaintext := fs.Arg(0)
+	// FQNs are compared and stored lower case, as the platform keeps them
+	if attributes, err = tdf.NormalizeAttributes(attributes); err != nil {
+		return err
+	}
+	for i := range fields {
+		if fields[i].Attributes, err = tdf.NormalizeAttributes(fields[i].Attributes); err != nil {
+			return err
+		}
+	}
+	policyMode, err := tdf.ParsePolicyMode(*policyModeName)
+	if err != nil {
+		return err
+	}
+	binding, err := tdf.ParseBinding(*bindingName)
+	if err != nil {
+		return err
+	}
+	if *output == "" && len(fields) == 0 && !*memo {
+		*output = "encrypted" + format.Extension()
+	}
+	if err := tdf.CheckOutput(*input, *output); err != nil {
+		return err
+	}
 
 	// Retrieve configuration from environment variables
 	platformEndpoint := getPlatformEndpoint()
@@ -73,51 +239,232 @@ func handleEncrypt() error {
 	}
 	defer client.Close()
 
-	// Open output file
-	outFile, err := os.Create(*output)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+	// Confirm the attributes name active values, so a typo cannot produce a
+	// file nobody can decrypt
+	if !*noVerify {
+		if _, err := tdf.VerifyAttributes(context.Background(), client, encryptAttributes(attributes, fields, *memo, table)); err != nil {
+			return fmt.Errorf("%w (pass -no-verify to skip this check offline)", err)
+		}
+	}
+	// Memo portions take their attributes from the markings table, so the
+	// caller's own access is checked once they are sealed
+	checkAccess := !*noVerify && clientID != "" && clientSecret != ""
+	if checkAccess && !*memo {
+		warnLockedOut(client, clientID, encryptAttributes(attributes, fields, false, table))
 	}
-	defer outFile.Close()
 
-	in := strings.NewReader(plaintext)
+	// Select the plaintext source. Files and stdin are streamed rather than
+	// read into memory so large binaries round-trip byte for byte.
+	var in io.ReadSeeker
+	switch *input {
+	case "":
+		in = strings.NewReader(fs.Arg(0))
+	case "-":
+		stdin, cleanup, err := tdf.SeekableInput(os.Stdin)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		in = stdin
+	default:
+		inFile, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %w", err)
+		}
+		defer inFile.Close()
+		in = inFile
+	}
 
-	// Ensure the platform endpoint has a valid HTTP/HTTPS scheme
-	baseKasURL := platformEndpoint
-	if !strings.HasPrefix(baseKasURL, "http://") && !strings.HasPrefix(baseKasURL, "https://") {
-		baseKasURL = "http://" + baseKasURL
+	if len(fields) > 0 {
+		sealer := tdf.NewSealer(client, tdf.KasURL(platformEndpoint), policyMode, binding)
+		return encryptDocument(sealer, in, *input, *output, fields, attributes, markingGuard(table, *overrideMarkings, clientID))
+	}
+	if *memo {
+		sealer := tdf.NewSealer(client, tdf.KasURL(platformEndpoint), policyMode, binding)
+		result, err := encryptMemo(sealer, in, *input, *output, table)
+		if err == nil && checkAccess {
+			warnLockedOut(client, clientID, result.Attributes())
+		}
+		return err
 	}
 
-	// Create nanoTDF configuration (nanoTDF is a compact binary format)
-	nanoConfig, err := client.NewNanoTDFConfig()
+	// Refuse to label marked plaintext below its classification before the
+	// output file is created
+	markings, err := memotdf.ScanMarkings(in)
 	if err != nil {
-		return fmt.Errorf("failed to create nanoTDF config: %w", err)
+		return err
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind input: %w", err)
+	}
+	if err := checkMarkings(table, markings, attributes, *overrideMarkings, clientID); err != nil {
+		return err
 	}
 
-	// Apply data attributes if specified
-	// Attributes define the access policy for the encrypted data
-	if len(attributes) > 0 {
-		if err := nanoConfig.SetAttributes(attributes); err != nil {
-			return fmt.Errorf("failed to set attributes: %w", err)
+	// ZTDF records the payload MIME type in its manifest
+	if format == tdf.FormatZTDF && *mimeType == "" {
+		name := *input
+		if name == "-" {
+			name = ""
 		}
+		if *mimeType, err = tdf.DetectMimeType(name, in); err != nil {
+			return err
+		}
+	}
+
+	// Encrypt next to the output path and rename once done, so a failure
+	// never truncates or removes a file already there
+	outFile, err := tdf.CreateOutput(*output, 0o644)
+	if err != nil {
+		return err
+	}
+	defer outFile.Discard()
+
+	// Perform the encryption operation against the platform's Key Access Service (KAS)
+	result, err := tdf.Encrypt(client, outFile, in, tdf.EncryptOptions{
+		Format:     format,
+		Attributes: attributes,
+		KasURL:     tdf.KasURL(platformEndpoint),
+		MimeType:   *mimeType,
+		PolicyMode: policyMode,
+		Binding:    binding,
+	})
+	if err != nil {
+		return err
+	}
+	if err := outFile.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully encrypted to %s: %s (%d bytes in, %d bytes out)\n",
+		format, *output, result.PlaintextSize, result.EncryptedSize)
+
+	return nil
+}
+
+// warnLockedOut warns when clientID would be denied data encrypted with
+// attrs, that is, when the caller could not decrypt its own output. The
+// encryption goes ahead either way.
+func warnLockedOut(client *sdk.SDK, clientID string, attrs []string) {
+	if len(attrs) == 0 {
+		return
 	}
+	attrs = slices.Compact(slices.Sorted(slices.Values(attrs)))
+	allowed, err := access.CanRead(context.Background(), client, access.Entity{Type: access.TypeClientID, ID: clientID}, attrs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check your own access to the output: %v\n", err)
+	} else if !allowed {
+		fmt.Fprintf(os.Stderr, "Warning: client %q is not entitled to %s and will not be able to decrypt the output\n", clientID, strings.Join(attrs, ", "))
+	}
+}
 
-	// Enable ECDSA policy binding for enhanced security
-	// This cryptographically binds the policy to the encrypted data
-	nanoConfig.EnableECDSAPolicyBinding()
+// encryptAttributes returns every attribute an encrypt run can apply: the
+// whole-input or -a attributes, those of each field, and in -memo mode
+// those of every marking in the table.
+func encryptAttributes(attributes []string, fields []doctdf.Field, memo bool, table *memotdf.Table) []string {
+	all := slices.Clone(attributes)
+	for _, f := range fields {
+		all = append(all, f.Attributes...)
+	}
+	if memo {
+		for _, attrs := range table.Markings {
+			all = append(all, attrs...)
+		}
+	}
+	return all
+}
 
-	// Configure the Key Access Service (KAS) endpoint
-	// KAS is responsible for key management and access control
-	if err := nanoConfig.SetKasURL(baseKasURL + "/kas"); err != nil {
-		return fmt.Errorf("failed to set KAS URL: %w", err)
+// encryptDocument encrypts the fields of a JSON or YAML document selected by
+// fields in place, writing the document to output (default "encrypted.json"
+// or "encrypted.yaml").
+func encryptDocument(sealer *tdf.Sealer, in io.Reader, name, output string, fields []doctdf.Field, attributes []string, guard doctdf.Guard) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	syntax := doctdf.DetectSyntax(name, data)
+	if output == "" {
+		output = "encrypted." + string(syntax)
+	}
+	if err := tdf.CheckOutput(name, output); err != nil {
+		return err
 	}
 
-	// Perform the encryption operation
-	if _, err := client.CreateNanoTDF(outFile, in, *nanoConfig); err != nil {
-		return fmt.Errorf("failed to create nanoTDF: %w", err)
+	doc, result, err := doctdf.Encrypt(sealer, data, syntax, fields, attributes, guard)
+	if err != nil {
+		return err
+	}
+	if err := tdf.WriteOutput(output, doc, 0o644); err != nil {
+		return err
 	}
 
-	fmt.Printf("Successfully encrypted to nanoTDF: %s\n", *output)
+	for _, p := range result.Unmatched {
+		fmt.Fprintf(os.Stderr, "Warning: %s matched nothing\n", p)
+	}
+	fmt.Printf("Successfully encrypted %d fields of the %s document to %s\n", len(result.Sealed), syntax, output)
+	for _, f := range result.Sealed {
+		fmt.Printf("  %s: %s\n", f.Path, strings.Join(f.Attributes, ", "))
+	}
+	return nil
+}
 
+// checkMarkings refuses markings above what attributes protect unless
+// override is set, in which case the override is logged with the agent
+// identity of OPENTDF_AGENT_JWT and the client.
+func checkMarkings(table *memotdf.Table, markings []memotdf.Marking, attributes []string, override bool, clientID string) error {
+	err := table.CheckMarkings(markings, attributes)
+	if err == nil {
+		return nil
+	}
+	if !override {
+		return fmt.Errorf("%w; add the missing -a attributes, or pass -override-markings if the markings are wrong", err)
+	}
+	fmt.Fprintf(os.Stderr, "AUDIT: classification-marking override by %s, client %q, attributes %v: %v\n",
+		agent.Identity(getAgentJWT()), clientID, attributes, err)
 	return nil
 }
+
+// markingGuard checks the markings of each value sealed by -field or
+// encrypt-csv against the attributes it is sealed with.
+func markingGuard(table *memotdf.Table, override bool, clientID string) func(location string, text []byte, attrs []string) error {
+	return func(path string, text []byte, attrs []string) error {
+		markings, err := memotdf.ScanMarkings(bytes.NewReader(text))
+		if err != nil {
+			return err
+		}
+		return checkMarkings(table, markings, attrs, override, clientID)
+	}
+}
+
+// encryptMemo encrypts the classified portions of memo markdown read from
+// name, writing the memo to output (default "encrypted.md"), and reports
+// what it sealed.
+func encryptMemo(sealer *tdf.Sealer, in io.Reader, name, output string, table *memotdf.Table) (memotdf.EncryptResult, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return memotdf.EncryptResult{}, fmt.Errorf("failed to read input: %w", err)
+	}
+	if output == "" {
+		output = "encrypted.md"
+	}
+	if err := tdf.CheckOutput(name, output); err != nil {
+		return memotdf.EncryptResult{}, err
+	}
+
+	doc, result, err := memotdf.Encrypt(sealer, data, table)
+	if err != nil {
+		return memotdf.EncryptResult{}, err
+	}
+	if err := tdf.WriteOutput(output, doc, 0o644); err != nil {
+		return memotdf.EncryptResult{}, err
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	fmt.Printf("Successfully encrypted %d portions of the memo to %s (%d left in the clear)\n", len(result.Sealed), output, result.Clear)
+	for _, p := range result.Sealed {
+		fmt.Printf("  line %d (%s): %s\n", p.Line, p.Marking, strings.Join(p.Attributes, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+)
+
+// handleInspect prints the nanoTDF header or ZTDF manifest of a file as
+// JSON. It never contacts the platform, so it also works for files the
+// caller is not entitled to decrypt.
+func handleInspect() error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("input file is required")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := tdf.Inspect(file)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
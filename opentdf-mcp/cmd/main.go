@@ -26,6 +26,30 @@ func main() {
 		err = handleEncrypt()
 	case "decrypt":
 		err = handleDecrypt()
+	case "relabel":
+		err = handleRelabel()
+	case "encrypt-csv":
+		err = handleEncryptCSV()
+	case "decrypt-csv":
+		err = handleDecryptCSV()
+	case "convert":
+		err = handleConvert()
+	case "inspect":
+		err = handleInspect()
+	case "encrypt-batch":
+		err = handleEncryptBatch()
+	case "decrypt-batch":
+		err = handleDecryptBatch()
+	case "suggest-attributes":
+		err = handleSuggestAttributes()
+	case "preview-access":
+		err = handlePreviewAccess()
+	case "access-matrix":
+		err = handleAccessMatrix()
+	case "verify-scenario":
+		err = handleVerifyScenario()
+	case "compare-entitlements":
+		err = handleCompareEntitlements()
 	case "get-entitlements":
 		err = handleGetEntitlements()
 	case "attributes":
@@ -62,21 +86,49 @@ func printUsage() {
 	fmt.Println("  opentdf-cli <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  encrypt             Encrypt data using TDF")
-	fmt.Println("  decrypt             Decrypt a TDF file")
-	fmt.Println("  get-entitlements    Get entitlements for an entity")
-	fmt.Println("  attributes list     List available attributes")
-	fmt.Println("  help                Show this help message")
+	fmt.Println("  encrypt               Encrypt data using TDF")
+	fmt.Println("  decrypt               Decrypt a TDF file")
+	fmt.Println("  relabel               Change the attribute policy of an existing TDF")
+	fmt.Println("  encrypt-csv           Encrypt CSV cells or rows, leaving chosen columns in the clear")
+	fmt.Println("  decrypt-csv           Decrypt a CSV from encrypt-csv, redacting cells you cannot open")
+	fmt.Println("  convert               Convert a TDF between nanoTDF and ZTDF")
+	fmt.Println("  inspect               Show the header or manifest of a TDF without decrypting")
+	fmt.Println("  encrypt-batch         Encrypt a directory using a labels manifest")
+	fmt.Println("  decrypt-batch         Decrypt a directory and report per-file access")
+	fmt.Println("  suggest-attributes    Propose attribute FQNs from signals in plaintext")
+	fmt.Println("  preview-access        Show which entities could decrypt data with given attributes")
+	fmt.Println("  access-matrix         Decide read access for entities x TDFs in one bulk request")
+	fmt.Println("  verify-scenario       Decrypt the scenario as every persona and diff against the expected matrix")
+	fmt.Println("  get-entitlements      Get entitlements for an entity")
+	fmt.Println("  compare-entitlements  Compare entitlements of two or more entities and what each can open")
+	fmt.Println("  attributes list       List available attributes")
+	fmt.Println("  help                  Show this help message")
 	fmt.Println()
 	fmt.Println("Environment Variables:")
 	fmt.Println("  OPENTDF_PLATFORM_ENDPOINT   Platform endpoint (default: http://localhost:8080)")
 	fmt.Println("  OPENTDF_CLIENT_ID           Client ID for authentication (default: opentdf-sdk)")
 	fmt.Println("  OPENTDF_CLIENT_SECRET       Client secret for authentication (default: secret)")
+	fmt.Println("  OPENTDF_AGENT_JWT           Agent token named in audit logs of -override-markings")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  opentdf-cli encrypt -a https://example.com/attr/class/value/secret \"Hello World\"")
+	fmt.Println("  opentdf-cli encrypt -f ztdf -i flight-log.csv -o flight-log.csv.tdf")
+	fmt.Println("  opentdf-cli inspect encrypted.ntdf")
+	fmt.Println("  opentdf-cli relabel -add https://example.com/attr/class/value/topsecret -o relabeled.tdf report.tdf")
+	fmt.Println("  opentdf-cli encrypt-csv -r csv-rules.yaml -o refueling.enc.csv kc-46-refueling-log-data.csv")
+	fmt.Println("  opentdf-cli decrypt-csv refueling.enc.csv")
+	fmt.Println("  opentdf-cli convert -f ztdf -o flight-log.tdf flight-log.ntdf")
+	fmt.Println("  opentdf-cli encrypt-batch -m scenario-labels.yaml -o encrypted-scenario usaf-refueling-scenario")
+	fmt.Println("  opentdf-cli decrypt-batch -o decrypted encrypted-scenario")
 	fmt.Println("  OPENTDF_CLIENT_ID=opentdf-sdk OPENTDF_CLIENT_SECRET=secret ./opentdf-cli decrypt encrypted.tdf")
+	fmt.Println("  opentdf-cli suggest-attributes maintenance-inspection-findings.csv")
+	fmt.Println("  opentdf-cli preview-access -a https://demo.usaf.mil/attr/flight_id/value/RCH2532101 -users masterprompt/users.yaml")
+	fmt.Println("  opentdf-cli encrypt-batch -m scenario-labels.yaml -o encrypted-scenario usaf-refueling-scenario &&")
+	fmt.Println("    opentdf-cli access-matrix -users masterprompt/users.yaml -o matrix.md encrypted-scenario")
+	fmt.Println("  opentdf-cli verify-scenario -junit scenario-report.xml scenario-expected.yaml")
 	fmt.Println("  opentdf-cli get-entitlements --identifier user@example.com --type email")
+	fmt.Println("  opentdf-cli encrypt-batch -m scenario-labels.yaml -o encrypted-scenario usaf-refueling-scenario &&")
+	fmt.Println("    opentdf-cli compare-entitlements -e julie.lee -e sarah.chen encrypted-scenario")
 	fmt.Println("  opentdf-cli attributes list -l")
 	fmt.Println()
 	fmt.Println("For MCP Server:")
@@ -103,3 +155,7 @@ func getClientSecret() string {
 	}
 	return "secret"
 }
+
+func getAgentJWT() string {
+	return os.Getenv("OPENTDF_AGENT_JWT")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opentdf/opentdf-mcp/internal/access"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleAccessMatrix decides, in one bulk request to the authorization
+// service, whether each entity may read each TDF or attribute set, and
+// prints the grid as a markdown table or JSON.
+//
+// Usage:
+//   access-matrix -users ../masterprompt/users.yaml [-json] [-o matrix.md] <tdf|dir>...
+//   access-matrix -e <[type:]id> [-e ...] -r <name>=<fqn>[,<fqn>]... [-r ...]
+//
+// The attributes of a TDF are read from its policy. A nanoTDF policy that is
+// encrypted cannot be read, so such files are refused; re-encrypt them with
+// a plaintext policy or give their attributes with -r.
+func handleAccessMatrix() error {
+	fs := flag.NewFlagSet("access-matrix", flag.ExitOnError)
+	var entityArgs, resourceArgs []string
+	fs.Func("e", "Entity as [clientId|email|username:]id (can be specified multiple times)", func(s string) error {
+		entityArgs = append(entityArgs, s)
+		return nil
+	})
+	fs.Func("r", "Attribute set as NAME=FQN[,FQN...] (can be specified multiple times)", func(s string) error {
+		resourceArgs = append(resourceArgs, s)
+		return nil
+	})
+	usersFile := fs.String("users", "", "Users file (e.g. masterprompt/users.yaml) whose client_ids are the columns")
+	asJSON := fs.Bool("json", false, "Print the matrix as JSON")
+	output := fs.String("o", "", "Also write the markdown table to this file")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	entities, err := access.ParseEntities(entityArgs)
+	if err != nil {
+		return err
+	}
+	if *usersFile != "" {
+		users, err := access.LoadUsers(*usersFile)
+		if err != nil {
+			return err
+		}
+		entities = append(entities, users...)
+	}
+	if len(entities) == 0 {
+		return fmt.Errorf("at least one -e entity or a -users file is required")
+	}
+
+	resources, err := access.FileResources(fs.Args())
+	if err != nil {
+		return err
+	}
+	for _, s := range resourceArgs {
+		res, err := access.ParseResource(s)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, res)
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf("at least one TDF, directory or -r attribute set is required")
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	clientID := getClientID()
+	clientSecret := getClientSecret()
+
+	// Create authenticated client
+	var opts []sdk.Option
+	if clientID != "" && clientSecret != "" {
+		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		opts = append(opts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create SDK client: %w", err)
+	}
+	defer client.Close()
+
+	matrix, err := access.Decide(context.Background(), client, entities, resources)
+	if err != nil {
+		return err
+	}
+
+	table := matrix.Markdown()
+	if *output != "" {
+		if err := os.WriteFile(*output, []byte(table), 0o644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+	}
+	if *asJSON {
+		out, err := json.MarshalIndent(matrix, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal matrix: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Print(table)
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opentdf/opentdf-mcp/internal/access"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handlePreviewAccess shows which entities could decrypt data encrypted with
+// a candidate attribute set, before anything is encrypted. The decisions
+// come from one bulk request to the authorization service.
+//
+// Usage:
+//   preview-access -a <fqn> [-a <fqn>]... -e <[type:]id> [-e ...]
+//   preview-access -a <fqn> -users ../masterprompt/users.yaml [-json]
+//
+// Entities are client IDs unless prefixed with "email:" or "username:".
+func handlePreviewAccess() error {
+	fs := flag.NewFlagSet("preview-access", flag.ExitOnError)
+	var attributes, entityArgs []string
+	fs.Func("a", "Candidate data attribute FQN (can be specified multiple times)", func(s string) error {
+		attributes = append(attributes, s)
+		return nil
+	})
+	fs.Func("e", "Entity as [clientId|email|username:]id (can be specified multiple times)", func(s string) error {
+		entityArgs = append(entityArgs, s)
+		return nil
+	})
+	usersFile := fs.String("users", "", "Users file (e.g. masterprompt/users.yaml) whose client_ids are previewed")
+	asJSON := fs.Bool("json", false, "Print the decisions as JSON")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	attributes, err := tdf.NormalizeAttributes(attributes)
+	if err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		return fmt.Errorf("at least one -a attribute is required")
+	}
+	entities, err := access.ParseEntities(entityArgs)
+	if err != nil {
+		return err
+	}
+	if *usersFile != "" {
+		users, err := access.LoadUsers(*usersFile)
+		if err != nil {
+			return err
+		}
+		entities = append(entities, users...)
+	}
+	if len(entities) == 0 {
+		return fmt.Errorf("at least one -e entity or a -users file is required")
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	clientID := getClientID()
+	clientSecret := getClientSecret()
+
+	// Create authenticated client
+	var opts []sdk.Option
+	if clientID != "" && clientSecret != "" {
+		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		opts = append(opts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create SDK client: %w", err)
+	}
+	defer client.Close()
+
+	matrix, err := access.Decide(context.Background(), client, entities, []access.Resource{{Name: "candidate", Attributes: attributes}})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(matrix.Decisions(0), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal decisions: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Println("Access to data encrypted with:")
+	for _, a := range attributes {
+		fmt.Printf("  %s\n", a)
+	}
+	allowed := 0
+	for _, d := range matrix.Decisions(0) {
+		decision := "DENY "
+		if d.Allowed {
+			decision = "ALLOW"
+			allowed++
+		}
+		fmt.Printf("  %s  %-24s %s\n", decision, d.Entity.Label(), d.Entity)
+	}
+	fmt.Printf("%d of %d entities could decrypt it\n", allowed, len(entities))
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleRelabel changes the attribute policy of an existing TDF by
+// decrypting and re-encrypting it in memory, and prints the old and new
+// policies side by side.
+//
+// Usage:
+//   relabel [-add <fqn>]... [-remove <fqn>]... -o <output> <input>
+//   relabel -set <fqn> [-set <fqn>]... -in-place <input>
+//
+// The output keeps the input's format, KAS, nanoTDF policy mode and binding,
+// and ZTDF MIME type and metadata. Plaintext is never written to disk.
+// -add and -remove need a readable policy (ZTDF or a plaintext-policy
+// nanoTDF); an encrypted nanoTDF policy can only be replaced with -set.
+func handleRelabel() error {
+	fs := flag.NewFlagSet("relabel", flag.ExitOnError)
+	output := fs.String("o", "", "Output file path")
+	inPlace := fs.Bool("in-place", false, "Replace the input file instead of writing -o")
+
+	var opts tdf.RelabelOptions
+	fs.Func("add", "Attribute to add; ZTDF and plaintext-policy nanoTDF only (can be specified multiple times)", func(s string) error {
+		opts.Add = append(opts.Add, s)
+		return nil
+	})
+	fs.Func("remove", "Attribute to remove; ZTDF and plaintext-policy nanoTDF only (can be specified multiple times)", func(s string) error {
+		opts.Remove = append(opts.Remove, s)
+		return nil
+	})
+	fs.Func("set", "Replace the whole policy with these attributes (can be specified multiple times)", func(s string) error {
+		opts.Set = append(opts.Set, s)
+		opts.Replace = true
+		return nil
+	})
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("input file is required")
+	}
+	inputFile := fs.Arg(0)
+	switch {
+	case *inPlace && *output != "":
+		return fmt.Errorf("cannot specify both -o and -in-place")
+	case *inPlace:
+		*output = inputFile
+	case *output == "":
+		return fmt.Errorf("-o output file or -in-place is required")
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	clientID := getClientID()
+	clientSecret := getClientSecret()
+	opts.KasURL = tdf.KasURL(platformEndpoint)
+
+	// Create authenticated client
+	var sdkOpts []sdk.Option
+	if clientID != "" && clientSecret != "" {
+		sdkOpts = append(sdkOpts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		sdkOpts = append(sdkOpts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, sdkOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create SDK client: %w", err)
+	}
+	defer client.Close()
+
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	// Write next to the destination and rename, so the original is only
+	// replaced once the relabelled TDF is complete
+	tmp, err := os.CreateTemp(filepath.Dir(*output), ".tmp-"+filepath.Base(*output)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	result, err := tdf.Relabel(context.Background(), client, tmp, file, opts)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set output permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), *output); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("Relabeled %s to %s (%s, %d bytes)\n\n", inputFile, *output, result.Format, result.EncryptedSize)
+	return tdf.WritePolicyDiff(os.Stdout, result)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentdf/opentdf-mcp/internal/batch"
+	"github.com/opentdf/opentdf-mcp/internal/scenario"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleVerifyScenario decrypts every TDF of the scenario as every persona of
+// an expected-matrix file and reports where access differs from the
+// expectation. It fails when any attempt does not turn out as expected.
+//
+// Usage:
+//   verify-scenario [-junit <report.xml>] [-j <workers>] [-json] <expected.yaml>
+//
+// Personas authenticate with the client credentials in the file; nothing is
+// written except the optional JUnit report.
+func handleVerifyScenario() error {
+	fs := flag.NewFlagSet("verify-scenario", flag.ExitOnError)
+	junitPath := fs.String("junit", "", "Write a JUnit XML report to this file")
+	workers := fs.Int("j", batch.DefaultWorkers, "Number of files to decrypt concurrently per persona")
+	asJSON := fs.Bool("json", false, "Print every outcome as JSON")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("expected-matrix file is required")
+	}
+	if *workers < 1 {
+		return fmt.Errorf("-j must be at least 1")
+	}
+
+	expected, err := scenario.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	platformEndpoint := getPlatformEndpoint()
+	connect := func(p scenario.Persona) (*sdk.SDK, error) {
+		client, err := sdk.New(platformEndpoint, sdk.WithClientCredentials(p.ClientID, p.ClientSecret, nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SDK client for %s: %w", p.ClientID, err)
+		}
+		return client, nil
+	}
+
+	outcomes, err := scenario.Verify(context.Background(), expected, connect, *workers)
+	if err != nil {
+		return err
+	}
+
+	if *junitPath != "" {
+		f, err := os.Create(*junitPath)
+		if err != nil {
+			return fmt.Errorf("failed to create JUnit report: %w", err)
+		}
+		err = scenario.WriteJUnit(f, outcomes)
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write JUnit report: %w", closeErr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	mismatches := scenario.Mismatches(outcomes)
+	if *asJSON {
+		out, err := json.MarshalIndent(outcomes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal outcomes: %w", err)
+		}
+		fmt.Println(string(out))
+	} else if len(mismatches) > 0 {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DOCUMENT\tPERSONA\tRESULT\tDETAIL")
+		for _, o := range mismatches {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", o.Document, o.Persona, o.Describe(), strings.ReplaceAll(o.Error, "\n", "; "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%d of %d decrypt attempts did not match the expected matrix", len(mismatches), len(outcomes))
+	}
+	if !*asJSON {
+		fmt.Printf("All %d decrypt attempts (%d documents x %d personas) match the expected matrix\n",
+			len(outcomes), len(expected.Documents), len(expected.Personas))
+	}
+	return nil
+}
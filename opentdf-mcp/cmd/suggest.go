@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/opentdf/opentdf-mcp/internal/suggest"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// handleSuggestAttributes scans plaintext for known signals (flight
+// identifiers, tail numbers, classification banners, maintenance
+// vocabulary) and proposes attribute FQNs with a confidence level and the
+// evidence found. Suggestions are checked against the attributes defined on
+// the platform unless -no-verify is given.
+//
+// Usage:
+//   suggest-attributes [-r <rules.yaml>] [-no-verify] [-json] <input|->
+func handleSuggestAttributes() error {
+	fs := flag.NewFlagSet("suggest-attributes", flag.ExitOnError)
+	rulesPath := fs.String("r", "", "Rules file (YAML or JSON) defining the signals (default: built-in scenario rules)")
+	noVerify := fs.Bool("no-verify", false, "Do not check the suggestions against the platform's attributes")
+	asJSON := fs.Bool("json", false, "Print the suggestions as JSON")
+
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		return fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if fs.NArg() < 1 {
+		return fmt.Errorf("input file is required (\"-\" reads stdin)")
+	}
+	inputFile := fs.Arg(0)
+
+	rules := suggest.DefaultRules()
+	if *rulesPath != "" {
+		var err error
+		if rules, err = suggest.LoadRules(*rulesPath); err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	var err error
+	if inputFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(inputFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	suggestions := rules.Scan(string(data))
+
+	if !*noVerify && len(suggestions) > 0 {
+		platformEndpoint := getPlatformEndpoint()
+		clientID := getClientID()
+		clientSecret := getClientSecret()
+
+		// Create authenticated client
+		var opts []sdk.Option
+		if clientID != "" && clientSecret != "" {
+			opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+		} else {
+			opts = append(opts, sdk.WithInsecurePlaintextConn())
+		}
+
+		client, err := sdk.New(platformEndpoint, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to create SDK client: %w", err)
+		}
+		defer client.Close()
+
+		// The suggestions are still useful without the platform, so a
+		// failed lookup leaves them unchecked
+		defined, err := tdf.ListAttributeValues(context.Background(), client)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: suggestions not checked against the platform: %v\n", err)
+		} else {
+			suggest.Check(suggestions, defined)
+		}
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(suggestions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal suggestions: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("No attribute signals found in %s\n", inputFile)
+		return nil
+	}
+	fmt.Printf("Suggested attributes for %s:\n", inputFile)
+	for _, s := range suggestions {
+		fmt.Printf("  %-6s  %-9s  %s (%d matches)\n", s.Confidence, s.Status, s.Attribute, s.Occurrences)
+		for _, e := range s.Evidence {
+			fmt.Printf("      line %d, %s: %q\n", e.Line, e.Rule, e.Text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+// Package access asks the platform's authorization service who can read
+// data. Entities are identified by client ID, email address or username,
+// and the decisions for many entities and attribute sets are made in a
+// single GetDecisionBulk request.
+package access
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	authorizationv2 "github.com/opentdf/platform/protocol/go/authorization/v2"
+	"github.com/opentdf/platform/protocol/go/entity"
+	"github.com/opentdf/platform/protocol/go/policy"
+	"github.com/opentdf/platform/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// Entity identifier types.
+const (
+	TypeClientID = "clientId"
+	TypeEmail    = "email"
+	TypeUsername = "username"
+)
+
+// ActionRead is the action decided on: reading, that is decrypting, data.
+const ActionRead = "read"
+
+// Entity is a subject whose access is decided.
+type Entity struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// Name labels the entity in tables, such as a persona's name.
+	Name string `json:"name,omitempty"`
+}
+
+// ParseEntity parses "[type:]id", where type is clientId (the default),
+// email or username.
+func ParseEntity(s string) (Entity, error) {
+	s = strings.TrimSpace(s)
+	typ, id, found := strings.Cut(s, ":")
+	if !found {
+		typ, id = TypeClientID, s
+	}
+	switch strings.ToLower(typ) {
+	case "clientid", "client_id", "client":
+		typ = TypeClientID
+	case "email":
+		typ = TypeEmail
+	case "username", "user":
+		typ = TypeUsername
+	default:
+		return Entity{}, fmt.Errorf("unsupported entity type %q in %q (expected clientId, email or username)", typ, s)
+	}
+	if id == "" {
+		return Entity{}, fmt.Errorf("entity %q has no identifier", s)
+	}
+	return Entity{Type: typ, ID: id}, nil
+}
+
+// ParseEntities parses each of list with ParseEntity.
+func ParseEntities(list []string) ([]Entity, error) {
+	entities := make([]Entity, 0, len(list))
+	for _, s := range list {
+		e, err := ParseEntity(s)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// LoadUsers reads the personas of a users file such as
+// masterprompt/users.yaml as client ID entities named after each user.
+func LoadUsers(path string) ([]Entity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+	var file struct {
+		Users []struct {
+			Name     string `yaml:"name"`
+			ClientID string `yaml:"client_id"`
+		} `yaml:"users"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse users file: %w", err)
+	}
+
+	var entities []Entity
+	for i, u := range file.Users {
+		if u.ClientID == "" {
+			return nil, fmt.Errorf("%s: user %d has no client_id", path, i+1)
+		}
+		entities = append(entities, Entity{Type: TypeClientID, ID: u.ClientID, Name: u.Name})
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("%s lists no users", path)
+	}
+	return entities, nil
+}
+
+// Label returns the name of the entity, or its identifier when it has none.
+func (e Entity) Label() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.ID
+}
+
+// String returns the entity as "type:id".
+func (e Entity) String() string {
+	return e.Type + ":" + e.ID
+}
+
+// Identifier returns the authorization service identifier of the entity.
+func (e Entity) Identifier() *authorizationv2.EntityIdentifier {
+	ent := &entity.Entity{
+		EphemeralId: e.String(),
+		Category:    entity.Entity_CATEGORY_SUBJECT,
+	}
+	switch e.Type {
+	case TypeEmail:
+		ent.EntityType = &entity.Entity_EmailAddress{EmailAddress: e.ID}
+	case TypeUsername:
+		ent.EntityType = &entity.Entity_UserName{UserName: e.ID}
+	default:
+		ent.EntityType = &entity.Entity_ClientId{ClientId: e.ID}
+	}
+	return &authorizationv2.EntityIdentifier{
+		Identifier: &authorizationv2.EntityIdentifier_EntityChain{
+			EntityChain: &entity.EntityChain{Entities: []*entity.Entity{ent}},
+		},
+	}
+}
+
+// Resource is a set of attribute values decided on together, such as the
+// policy of one TDF.
+type Resource struct {
+	Name       string   `json:"name"`
+	Attributes []string `json:"attributes"`
+}
+
+// Matrix holds the read decisions for every entity on every resource.
+type Matrix struct {
+	Entities  []Entity   `json:"entities"`
+	Resources []Resource `json:"resources"`
+	// Allowed[r][e] reports whether Entities[e] may read Resources[r].
+	Allowed [][]bool `json:"allowed"`
+}
+
+// Decide asks the authorization service, in one GetDecisionBulk request,
+// whether each entity may read each resource. A resource without attributes
+// is readable by everyone and is not sent.
+func Decide(ctx context.Context, client *sdk.SDK, entities []Entity, resources []Resource) (*Matrix, error) {
+	m := &Matrix{Entities: entities, Resources: resources, Allowed: make([][]bool, len(resources))}
+	var requested []*authorizationv2.Resource
+	for r, res := range resources {
+		m.Allowed[r] = make([]bool, len(entities))
+		if len(res.Attributes) == 0 {
+			for e := range entities {
+				m.Allowed[r][e] = true
+			}
+			continue
+		}
+		requested = append(requested, &authorizationv2.Resource{
+			EphemeralId: strconv.Itoa(r),
+			Resource: &authorizationv2.Resource_AttributeValues_{
+				AttributeValues: &authorizationv2.Resource_AttributeValues{Fqns: res.Attributes},
+			},
+		})
+	}
+	if len(requested) == 0 || len(entities) == 0 {
+		return m, nil
+	}
+
+	req := &authorizationv2.GetDecisionBulkRequest{}
+	for _, e := range entities {
+		req.DecisionRequests = append(req.DecisionRequests, &authorizationv2.GetDecisionMultiResourceRequest{
+			EntityIdentifier: e.Identifier(),
+			Action:           &policy.Action{Name: ActionRead},
+			Resources:        requested,
+		})
+	}
+	resp, err := client.AuthorizationV2.GetDecisionBulk(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access decisions: %w", err)
+	}
+
+	// Responses follow the order of the requests
+	responses := resp.GetDecisionResponses()
+	if len(responses) != len(entities) {
+		return nil, fmt.Errorf("authorization service returned %d decision sets for %d entities", len(responses), len(entities))
+	}
+	for e, dr := range responses {
+		for _, d := range dr.GetResourceDecisions() {
+			r, err := strconv.Atoi(d.GetEphemeralResourceId())
+			if err != nil || r < 0 || r >= len(resources) {
+				return nil, fmt.Errorf("authorization service returned a decision for unknown resource %q", d.GetEphemeralResourceId())
+			}
+			m.Allowed[r][e] = d.GetDecision() == authorizationv2.Decision_DECISION_PERMIT
+		}
+	}
+	return m, nil
+}
+
+// CanRead reports whether e may read data with attrs.
+func CanRead(ctx context.Context, client *sdk.SDK, e Entity, attrs []string) (bool, error) {
+	m, err := Decide(ctx, client, []Entity{e}, []Resource{{Attributes: attrs}})
+	if err != nil {
+		return false, err
+	}
+	return m.Allowed[0][0], nil
+}
+
+// Decision is the read decision for one entity on one resource.
+type Decision struct {
+	Entity  Entity `json:"entity"`
+	Allowed bool   `json:"allowed"`
+}
+
+// Decisions lists the decision for every entity on Resources[r].
+func (m *Matrix) Decisions(r int) []Decision {
+	decisions := make([]Decision, len(m.Entities))
+	for e, ent := range m.Entities {
+		decisions[e] = Decision{Entity: ent, Allowed: m.Allowed[r][e]}
+	}
+	return decisions
+}
+
+// Markdown renders the matrix as a table with a row per resource and a
+// column per entity, ✅ where reading is allowed and ❌ where it is denied.
+func (m *Matrix) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Resource |")
+	for _, e := range m.Entities {
+		fmt.Fprintf(&b, " %s |", e.Label())
+	}
+	b.WriteString("\n|----------|")
+	for range m.Entities {
+		b.WriteString(":---:|")
+	}
+	b.WriteString("\n")
+	for r, res := range m.Resources {
+		fmt.Fprintf(&b, "| %s |", res.Name)
+		for e := range m.Entities {
+			mark := "❌"
+			if m.Allowed[r][e] {
+				mark = "✅"
+			}
+			fmt.Fprintf(&b, " %s |", mark)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Entitlement is an attribute value an entity is entitled to and the
+// actions it may take on data carrying it.
+type Entitlement struct {
+	Attribute string   `json:"attribute"`
+	Actions   []string `json:"actions"`
+}
+
+// GetEntitlements returns the entitlements of e, flattened from the
+// authorization service response and sorted by attribute. With
+// comprehensiveHierarchy, values below an entitled value of a hierarchy
+// attribute are listed too.
+func GetEntitlements(ctx context.Context, client *sdk.SDK, e Entity, comprehensiveHierarchy bool) ([]Entitlement, error) {
+	req := &authorizationv2.GetEntitlementsRequest{EntityIdentifier: e.Identifier()}
+	if comprehensiveHierarchy {
+		req.WithComprehensiveHierarchy = &comprehensiveHierarchy
+	}
+	resp, err := client.AuthorizationV2.GetEntitlements(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entitlements for %s: %w", e, err)
+	}
+
+	byAttr := map[string][]string{}
+	for _, ee := range resp.GetEntitlements() {
+		for fqn, list := range ee.GetActionsPerAttributeValueFqn() {
+			for _, a := range list.GetActions() {
+				name := a.GetName()
+				if name != "" && !slices.Contains(byAttr[fqn], name) {
+					byAttr[fqn] = append(byAttr[fqn], name)
+				}
+			}
+			if _, ok := byAttr[fqn]; !ok {
+				byAttr[fqn] = []string{}
+			}
+		}
+	}
+
+	entitlements := make([]Entitlement, 0, len(byAttr))
+	for fqn, actions := range byAttr {
+		slices.Sort(actions)
+		entitlements = append(entitlements, Entitlement{Attribute: fqn, Actions: actions})
+	}
+	slices.SortFunc(entitlements, func(a, b Entitlement) int { return strings.Compare(a.Attribute, b.Attribute) })
+	return entitlements, nil
+}
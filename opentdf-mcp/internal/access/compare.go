@@ -0,0 +1,139 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// Comparison sets the entitlements of several entities side by side, and
+// optionally what each of them can open in a set of documents.
+type Comparison struct {
+	// Shared are the attribute values every entity is entitled to.
+	Shared   []string           `json:"shared"`
+	Entities []EntityComparison `json:"entities"`
+	// Matrix holds the read decisions on the documents compared, if any.
+	Matrix *Matrix `json:"matrix,omitempty"`
+}
+
+// EntityComparison is what sets one entity of a Comparison apart.
+type EntityComparison struct {
+	Entity Entity `json:"entity"`
+	// Unique are the attribute values of the entity that not every other
+	// entity has; with two entities, the values only this one has.
+	Unique []string `json:"unique"`
+	// Opens are the documents the entity can open and at least one other
+	// entity cannot.
+	Opens []string `json:"opens,omitempty"`
+}
+
+// Compare fetches the entitlements of each entity and splits them into the
+// values all of them share and those that set each apart. With documents,
+// whether each entity may read each of them is decided in one bulk request.
+func Compare(ctx context.Context, client *sdk.SDK, entities []Entity, documents []Resource, comprehensiveHierarchy bool) (*Comparison, error) {
+	if len(entities) < 2 {
+		return nil, fmt.Errorf("at least two entities are needed for a comparison")
+	}
+	values := make([][]string, len(entities))
+	for i, e := range entities {
+		entitlements, err := GetEntitlements(ctx, client, e, comprehensiveHierarchy)
+		if err != nil {
+			return nil, err
+		}
+		for _, ent := range entitlements {
+			values[i] = append(values[i], ent.Attribute)
+		}
+	}
+
+	c := compareValues(entities, values)
+	if len(documents) > 0 {
+		m, err := Decide(ctx, client, entities, documents)
+		if err != nil {
+			return nil, err
+		}
+		c.Matrix = m
+		for e := range c.Entities {
+			c.Entities[e].Opens = m.opens(e)
+		}
+	}
+	return c, nil
+}
+
+// compareValues splits the sorted attribute values of each entity into
+// those shared by all and those that are not.
+func compareValues(entities []Entity, values [][]string) *Comparison {
+	count := map[string]int{}
+	for _, list := range values {
+		for _, v := range list {
+			count[v]++
+		}
+	}
+
+	c := &Comparison{Shared: []string{}, Entities: make([]EntityComparison, len(entities))}
+	for v, n := range count {
+		if n == len(entities) {
+			c.Shared = append(c.Shared, v)
+		}
+	}
+	slices.Sort(c.Shared)
+	for i, e := range entities {
+		c.Entities[i] = EntityComparison{Entity: e, Unique: []string{}}
+		for _, v := range values[i] {
+			if count[v] < len(entities) {
+				c.Entities[i].Unique = append(c.Entities[i].Unique, v)
+			}
+		}
+	}
+	return c
+}
+
+// opens lists the resources Entities[e] may read and some other entity may
+// not.
+func (m *Matrix) opens(e int) []string {
+	var names []string
+	for r, res := range m.Resources {
+		if m.Allowed[r][e] && slices.Contains(m.Allowed[r], false) {
+			names = append(names, res.Name)
+		}
+	}
+	return names
+}
+
+// Summary renders the comparison as text, e.g. for a terminal or an agent.
+func (c *Comparison) Summary() string {
+	var b strings.Builder
+	labels := make([]string, len(c.Entities))
+	for i, ec := range c.Entities {
+		labels[i] = ec.Entity.Label()
+	}
+	fmt.Fprintf(&b, "Shared by %s (%d):\n", strings.Join(labels, ", "), len(c.Shared))
+	writeList(&b, c.Shared)
+	for _, ec := range c.Entities {
+		if len(c.Entities) == 2 {
+			fmt.Fprintf(&b, "Only %s (%d):\n", ec.Entity.Label(), len(ec.Unique))
+		} else {
+			fmt.Fprintf(&b, "%s, not shared by all (%d):\n", ec.Entity.Label(), len(ec.Unique))
+		}
+		writeList(&b, ec.Unique)
+	}
+	if c.Matrix == nil {
+		return b.String()
+	}
+	for _, ec := range c.Entities {
+		fmt.Fprintf(&b, "Documents %s can open that others cannot (%d):\n", ec.Entity.Label(), len(ec.Opens))
+		writeList(&b, ec.Opens)
+	}
+	return b.String()
+}
+
+func writeList(b *strings.Builder, list []string) {
+	if len(list) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, s := range list {
+		fmt.Fprintf(b, "  %s\n", s)
+	}
+}
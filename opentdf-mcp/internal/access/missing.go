@@ -0,0 +1,103 @@
+package access
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/platform/protocol/go/policy"
+	"github.com/opentdf/platform/protocol/go/policy/attributes"
+	"github.com/opentdf/platform/sdk"
+)
+
+// Gap is an attribute of a policy that an entity is not entitled to read.
+type Gap struct {
+	// Attribute is the FQN of the attribute definition.
+	Attribute string `json:"attribute"`
+	// Values are the value FQNs of the policy the entity lacks.
+	Values []string `json:"values"`
+	// AnyOf is set when the attribute rule is anyOf, so an entitlement to
+	// any one of Values would do.
+	AnyOf bool `json:"anyOf,omitempty"`
+}
+
+// String names the missing values briefly, e.g. "flight_id/rch2532102" or
+// "one of needtoknow/a or needtoknow/b".
+func (g Gap) String() string {
+	names := make([]string, len(g.Values))
+	for i, v := range g.Values {
+		names[i] = ShortName(v)
+	}
+	if g.AnyOf && len(names) > 1 {
+		return "one of " + strings.Join(names, " or ")
+	}
+	return strings.Join(names, ", ")
+}
+
+// ShortName abbreviates an attribute value FQN to "name/value".
+func ShortName(fqn string) string {
+	v, err := sdk.NewAttributeValueFQN(fqn)
+	if err != nil {
+		return fqn
+	}
+	return v.Name() + "/" + v.Value()
+}
+
+// MissingEntitlements compares the attribute values of a policy with the
+// read entitlements of e and returns what e lacks, per attribute
+// definition. The rules of the definitions come from the policy service;
+// when they cannot be looked up every value is treated as required.
+func MissingEntitlements(ctx context.Context, client *sdk.SDK, e Entity, attrs []string) ([]Gap, error) {
+	entitlements, err := GetEntitlements(ctx, client, e, true)
+	if err != nil {
+		return nil, err
+	}
+	entitled := map[string]bool{}
+	for _, ent := range entitlements {
+		if slices.Contains(ent.Actions, ActionRead) {
+			entitled[strings.ToLower(ent.Attribute)] = true
+		}
+	}
+
+	rules := map[string]policy.AttributeRuleTypeEnum{}
+	resp, err := client.Attributes.GetAttributeValuesByFqns(ctx, &attributes.GetAttributeValuesByFqnsRequest{Fqns: attrs})
+	if err == nil {
+		for _, av := range resp.GetFqnAttributeValues() {
+			def := av.GetAttribute()
+			rules[strings.ToLower(def.GetFqn())] = def.GetRule()
+		}
+	}
+	return missing(attrs, entitled, rules), nil
+}
+
+// missing groups attrs by attribute definition and keeps the values not in
+// entitled. An anyOf attribute is satisfied by any one entitled value;
+// allOf and hierarchy attributes need every value, since comprehensive
+// hierarchy entitlements already include the values below an entitled one.
+func missing(attrs []string, entitled map[string]bool, rules map[string]policy.AttributeRuleTypeEnum) []Gap {
+	var gaps []Gap
+	index := map[string]int{}
+	satisfied := map[string]bool{}
+	for _, a := range attrs {
+		v, err := sdk.NewAttributeValueFQN(a)
+		if err != nil {
+			continue
+		}
+		def := strings.ToLower(v.Prefix().String())
+		anyOf := rules[def] == policy.AttributeRuleTypeEnum_ATTRIBUTE_RULE_TYPE_ENUM_ANY_OF
+		if entitled[strings.ToLower(a)] {
+			if anyOf {
+				satisfied[def] = true
+			}
+			continue
+		}
+		i, ok := index[def]
+		if !ok {
+			i = len(gaps)
+			index[def] = i
+			gaps = append(gaps, Gap{Attribute: def, AnyOf: anyOf})
+		}
+		gaps[i].Values = append(gaps[i].Values, a)
+	}
+	return slices.DeleteFunc(gaps, func(g Gap) bool { return satisfied[g.Attribute] })
+}
@@ -0,0 +1,111 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+)
+
+// ErrPolicyUnreadable is returned for a nanoTDF whose policy is encrypted:
+// its attributes cannot be read without KAS, and nothing outside the file
+// can vouch for them. Encrypt with a plaintext policy (policy: plaintext in
+// an encrypt-batch manifest) or give the attribute set explicitly.
+var ErrPolicyUnreadable = errors.New("the nanoTDF policy is encrypted, so its attributes cannot be read; encrypt it with a plaintext policy or give its attribute set instead")
+
+// ParseResource parses the command line form "NAME=FQN[,FQN...]" of an
+// attribute set. The last "=" separates the name, since FQNs never contain
+// one.
+func ParseResource(s string) (Resource, error) {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return Resource{}, fmt.Errorf("invalid attribute set %q: expected NAME=FQN[,FQN...]", s)
+	}
+	attrs, err := tdf.NormalizeAttributes(strings.Split(s[i+1:], ","))
+	if err != nil {
+		return Resource{}, err
+	}
+	return Resource{Name: s[:i], Attributes: attrs}, nil
+}
+
+// FileResources returns a resource per TDF in paths, walking directories,
+// with the attributes of its policy. A nanoTDF whose policy is encrypted
+// cannot be read without KAS and is refused with ErrPolicyUnreadable; give
+// its attribute set instead.
+func FileResources(paths []string) ([]Resource, error) {
+	var resources []Resource
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			res, err := fileResource(filepath.Dir(root), root)
+			if err != nil {
+				return nil, err
+			}
+			res.Name = root
+			resources = append(resources, res)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			res, err := fileResource(root, p)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, res)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// fileResource reads the policy attributes of the TDF at p, named by its
+// path relative to dir.
+func fileResource(dir, p string) (Resource, error) {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return Resource{}, fmt.Errorf("failed to resolve %s: %w", p, err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	f, err := os.Open(p)
+	if err != nil {
+		return Resource{}, fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer f.Close()
+	info, err := tdf.Inspect(f)
+	if err != nil {
+		return Resource{}, fmt.Errorf("%s: %w", p, err)
+	}
+
+	res := Resource{Name: rel}
+	var readable bool
+	if res.Attributes, readable = info.PolicyAttributes(); !readable {
+		return Resource{}, fmt.Errorf("%s: %w", p, ErrPolicyUnreadable)
+	}
+	if res.Attributes, err = tdf.NormalizeAttributes(res.Attributes); err != nil {
+		return Resource{}, fmt.Errorf("%s: %w", p, err)
+	}
+	return res, nil
+}
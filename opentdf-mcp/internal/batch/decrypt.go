@@ -0,0 +1,193 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// StatusAllowed is reported for files the caller was able to decrypt. Other
+// decrypt outcomes use the tdf.ErrorKind values.
+const StatusAllowed = "allowed"
+
+// DefaultWorkers bounds concurrent decrypts when DecryptOptions.Workers is 0.
+const DefaultWorkers = 4
+
+// DecryptOptions configures DecryptDir.
+type DecryptOptions struct {
+	// OutputDir receives the plaintext of every allowed file, mirroring the
+	// source tree. When empty, files are decrypted only to test access.
+	OutputDir string
+	Workers   int
+}
+
+// DecryptResult describes the outcome for a single TDF.
+type DecryptResult struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Format string `json:"format,omitempty"`
+	Output string `json:"output,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DecryptDir attempts to decrypt every file under srcDir with a bounded
+// pool of workers and reports per file whether access was allowed, denied,
+// the file was malformed, or the platform could not be reached. A failure
+// on one file never stops the others. Results are returned in walk order.
+func DecryptDir(ctx context.Context, client *sdk.SDK, srcDir string, opts DecryptOptions) ([]DecryptResult, error) {
+	absOut := ""
+	if opts.OutputDir != "" {
+		var err error
+		if absOut, err = filepath.Abs(opts.OutputDir); err != nil {
+			return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+		}
+	}
+
+	var paths []string
+	err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Never descend into the output tree or hidden directories
+			if abs, _ := filepath.Abs(p); abs == absOut || (p != srcDir && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && !strings.HasPrefix(d.Name(), ".") {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", srcDir, err)
+	}
+
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	// Restore the original file names when the directory was produced by
+	// EncryptDir; otherwise just drop the TDF extension. The state file
+	// comes with the untrusted input, so a name that would escape the output
+	// directory is ignored.
+	origNames := map[string]string{}
+	if state, err := loadState(filepath.Join(srcDir, StateFile)); err == nil {
+		for src, entry := range state {
+			if filepath.IsLocal(filepath.FromSlash(src)) {
+				origNames[entry.Output] = src
+			}
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	results := make([]DecryptResult, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = decryptFile(ctx, client, srcDir, paths[i], origNames, opts.OutputDir)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+func decryptFile(ctx context.Context, client *sdk.SDK, srcDir, p string, origNames map[string]string, outDir string) DecryptResult {
+	relOS, _ := filepath.Rel(srcDir, p)
+	rel := filepath.ToSlash(relOS)
+	res := DecryptResult{Path: rel}
+
+	fail := func(kind tdf.ErrorKind, err error) DecryptResult {
+		res.Status = string(kind)
+		res.Error = err.Error()
+		return res
+	}
+
+	in, err := os.Open(p)
+	if err != nil {
+		return fail(tdf.KindOther, fmt.Errorf("failed to open input file: %w", err))
+	}
+	defer in.Close()
+
+	format, err := tdf.DetectFormat(in)
+	if err != nil {
+		return fail(tdf.KindMalformed, err)
+	}
+	res.Format = string(format)
+
+	var out io.Writer = io.Discard
+	var tmp *os.File
+	if outDir != "" {
+		outRel, ok := origNames[rel]
+		if !ok {
+			outRel = strings.TrimSuffix(rel, filepath.Ext(rel))
+		}
+		dst := filepath.Join(outDir, filepath.FromSlash(outRel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			return fail(tdf.KindOther, fmt.Errorf("failed to create output directory: %w", err))
+		}
+		// CreateTemp uses owner-only permissions; the file is renamed into
+		// place only once decryption has fully succeeded.
+		if tmp, err = os.CreateTemp(filepath.Dir(dst), ".tmp-"+filepath.Base(dst)+"-*"); err != nil {
+			return fail(tdf.KindOther, fmt.Errorf("failed to create output file: %w", err))
+		}
+		defer os.Remove(tmp.Name())
+		out = tmp
+		res.Output = outRel
+	}
+
+	counter := &countingWriter{}
+	err = tdf.Decrypt(ctx, client, io.MultiWriter(out, counter), in, format)
+	if tmp != nil {
+		if closeErr := tmp.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write output file: %w", closeErr)
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), filepath.Join(outDir, filepath.FromSlash(res.Output)))
+		}
+	}
+	if err != nil {
+		res.Output = ""
+		return fail(tdf.ClassifyError(err), err)
+	}
+
+	res.Status = StatusAllowed
+	res.Size = counter.n
+	return res
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
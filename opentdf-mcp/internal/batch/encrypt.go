@@ -0,0 +1,264 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+)
+
+// StateFile is kept in the output directory to record what each output was
+// produced from, so unchanged files are skipped on the next run.
+const StateFile = ".opentdf-batch.json"
+
+// Status values reported per file.
+const (
+	StatusEncrypted = "encrypted"
+	StatusUnchanged = "unchanged"
+	StatusUnmatched = "unmatched"
+	StatusExcluded  = "excluded"
+	StatusFailed    = "failed"
+)
+
+// EncryptOptions configures EncryptDir.
+type EncryptOptions struct {
+	// Format overrides the manifest's format when set.
+	Format string
+	KasURL string
+	// Force re-encrypts every matched file even if it is unchanged.
+	Force bool
+}
+
+// Result describes what happened to a single source file.
+type Result struct {
+	Path          string   `json:"path"`
+	Output        string   `json:"output,omitempty"`
+	Status        string   `json:"status"`
+	Attributes    []string `json:"attributes,omitempty"`
+	PlaintextSize int64    `json:"plaintextSize,omitempty"`
+	EncryptedSize int64    `json:"encryptedSize,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+type stateEntry struct {
+	SHA256     string   `json:"sha256"`
+	Attributes []string `json:"attributes"`
+	Format     string   `json:"format"`
+	Output     string   `json:"output"`
+	// Policy is the nanoTDF policy mode, empty for the default.
+	Policy string `json:"policy,omitempty"`
+}
+
+// EncryptDir encrypts every file under srcDir that the manifest matches into
+// the mirrored location under outDir, replacing the source extension with
+// the format's extension (kc-46-flight-log-data.csv becomes
+// kc-46-flight-log-data.ntdf). Per-file failures are reported in the results
+// and do not stop the run; the returned error is reserved for problems that
+// affect the whole batch.
+func EncryptDir(client *sdk.SDK, srcDir, outDir string, m *Manifest, opts EncryptOptions) ([]Result, error) {
+	formatName := opts.Format
+	if formatName == "" {
+		formatName = m.Format
+	}
+	format, err := tdf.ParseFormat(formatName)
+	if err != nil {
+		return nil, err
+	}
+	// The policy mode only applies to nanoTDF; ZTDF policies are readable
+	var policyMode tdf.PolicyMode
+	if format == tdf.FormatNano {
+		if policyMode, err = tdf.ParsePolicyMode(m.Policy); err != nil {
+			return nil, err
+		}
+	}
+
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	statePath := filepath.Join(outDir, StateFile)
+	state, err := loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	outputs := map[string]string{}
+	walkErr := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Never descend into the output tree or hidden directories
+			if abs, _ := filepath.Abs(p); abs == absOut || (p != srcDir && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		relOS, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		rel := filepath.ToSlash(relOS)
+
+		if m.Excluded(rel) {
+			results = append(results, Result{Path: rel, Status: StatusExcluded})
+			return nil
+		}
+		attrs, matched := m.AttributesFor(rel)
+		if !matched {
+			results = append(results, Result{Path: rel, Status: StatusUnmatched})
+			return nil
+		}
+
+		outRel := strings.TrimSuffix(rel, filepath.Ext(rel)) + format.Extension()
+		res := Result{Path: rel, Output: outRel, Attributes: attrs}
+		if other, ok := outputs[outRel]; ok {
+			res.Status = StatusFailed
+			res.Error = fmt.Sprintf("output %s collides with %s", outRel, other)
+			results = append(results, res)
+			return nil
+		}
+		outputs[outRel] = rel
+
+		entry, err := encryptFile(client, p, filepath.Join(outDir, filepath.FromSlash(outRel)), state[rel], attrs, format, policyMode, opts, &res)
+		if err != nil {
+			res.Status = StatusFailed
+			res.Error = err.Error()
+		} else {
+			entry.Output = outRel
+			state[rel] = entry
+		}
+		results = append(results, res)
+		return nil
+	})
+	if walkErr != nil {
+		return results, fmt.Errorf("failed to walk %s: %w", srcDir, walkErr)
+	}
+
+	if err := saveState(statePath, state); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// encryptFile encrypts src to dst unless the previous state shows the same
+// plaintext was already encrypted with the same attributes, format and
+// policy mode.
+func encryptFile(client *sdk.SDK, src, dst string, prev stateEntry, attrs []string, format tdf.Format, policyMode tdf.PolicyMode, opts EncryptOptions, res *Result) (stateEntry, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return stateEntry{}, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer in.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, in)
+	if err != nil {
+		return stateEntry{}, fmt.Errorf("failed to hash input file: %w", err)
+	}
+	res.PlaintextSize = size
+	entry := stateEntry{
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		Attributes: attrs,
+		Format:     string(format),
+		Policy:     string(policyMode),
+	}
+
+	if !opts.Force && prev.SHA256 == entry.SHA256 && prev.Format == entry.Format && prev.Policy == entry.Policy && slices.Equal(prev.Attributes, attrs) {
+		if info, err := os.Stat(dst); err == nil {
+			res.Status = StatusUnchanged
+			res.EncryptedSize = info.Size()
+			return entry, nil
+		}
+	}
+
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return stateEntry{}, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+	mimeType := ""
+	if format == tdf.FormatZTDF {
+		if mimeType, err = tdf.DetectMimeType(src, in); err != nil {
+			return stateEntry{}, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return stateEntry{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Encrypt to a temporary file and rename so an interrupted run never
+	// leaves a truncated TDF that a later run would treat as current.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-"+filepath.Base(dst)+"-*")
+	if err != nil {
+		return stateEntry{}, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	result, err := tdf.Encrypt(client, tmp, in, tdf.EncryptOptions{
+		Format:     format,
+		Attributes: attrs,
+		KasURL:     opts.KasURL,
+		MimeType:   mimeType,
+		PolicyMode: policyMode,
+	})
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+	if err != nil {
+		return stateEntry{}, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return stateEntry{}, fmt.Errorf("failed to set output permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return stateEntry{}, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	res.Status = StatusEncrypted
+	res.EncryptedSize = result.EncryptedSize
+	return entry, nil
+}
+
+func loadState(path string) (map[string]stateEntry, error) {
+	state := map[string]stateEntry{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse batch state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func saveState(path string, state map[string]stateEntry) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write batch state: %w", err)
+	}
+	return nil
+}
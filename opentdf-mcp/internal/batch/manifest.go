@@ -0,0 +1,173 @@
+// Package batch encrypts whole directory trees according to a labels
+// manifest that maps file globs to attribute FQNs, and decrypts them again
+// with a per-file access report.
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest maps files to the data attributes they are encrypted with.
+//
+// Example (YAML; the equivalent JSON document is accepted as well):
+//
+//	namespace: https://demo.usaf.mil
+//	format: nano
+//	policy: plaintext
+//	rules:
+//	  - match: "kc-46-*"
+//	    attributes: [flight_id/value/RCH2532101]
+//	  - match: "*.csv"
+//	    attributes: [classification/value/secret-fictional]
+//
+// Every rule whose glob matches a file contributes its attributes; files
+// matched by no rule are left unencrypted and reported as unmatched.
+type Manifest struct {
+	// Namespace is prepended to short attribute references such as
+	// "flight_id/value/RCH2532101".
+	Namespace string `yaml:"namespace" json:"namespace"`
+	// Format is the default container format ("nano" or "ztdf").
+	Format string `yaml:"format" json:"format"`
+	// Policy is the nanoTDF policy mode ("encrypted", the default, or
+	// "plaintext"). Only a plaintext policy lets access-matrix and
+	// compare-entitlements read the attributes from the file itself; ZTDF
+	// policies are always readable.
+	Policy string `yaml:"policy" json:"policy"`
+	// Exclude lists globs for files that are never encrypted.
+	Exclude []string `yaml:"exclude" json:"exclude"`
+	Rules   []Rule   `yaml:"rules" json:"rules"`
+}
+
+// Rule applies Attributes to every file matching the Match glob. Globs
+// without a slash are matched against the file name, otherwise against the
+// slash-separated path relative to the source directory.
+type Rule struct {
+	Match      string   `yaml:"match" json:"match"`
+	Attributes []string `yaml:"attributes" json:"attributes"`
+}
+
+// LoadManifest reads a YAML or JSON manifest from path.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest parses a YAML or JSON manifest and validates its globs and
+// attribute references.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(m.Rules) == 0 {
+		return nil, fmt.Errorf("manifest has no rules")
+	}
+	if _, err := tdf.ParsePolicyMode(m.Policy); err != nil {
+		return nil, fmt.Errorf("manifest policy: %w", err)
+	}
+
+	for i, r := range m.Rules {
+		if r.Match == "" {
+			return nil, fmt.Errorf("rule %d: match is required", i+1)
+		}
+		if _, err := path.Match(r.Match, ""); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid glob %q: %w", i+1, r.Match, err)
+		}
+		for _, a := range r.Attributes {
+			if _, err := m.expandFQN(a); err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+		}
+	}
+	for _, g := range m.Exclude {
+		if _, err := path.Match(g, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude glob %q: %w", g, err)
+		}
+	}
+
+	return &m, nil
+}
+
+// expandFQN turns a short attribute reference into a full FQN using the
+// manifest namespace and normalizes it as the platform stores it.
+func (m *Manifest) expandFQN(a string) (string, error) {
+	fqn, err := tdf.ExpandAttribute(m.Namespace, a)
+	if err != nil {
+		return "", fmt.Errorf("%w in the manifest", err)
+	}
+	normalized, err := tdf.NormalizeAttributes([]string{fqn})
+	if err != nil {
+		return "", fmt.Errorf("%w in the manifest", err)
+	}
+	return normalized[0], nil
+}
+
+// AttributeValues returns every attribute FQN the manifest can apply, for
+// checking them against the platform before anything is encrypted.
+func (m *Manifest) AttributeValues() []string {
+	var attrs []string
+	for _, r := range m.Rules {
+		for _, a := range r.Attributes {
+			// Already validated by ParseManifest
+			fqn, _ := m.expandFQN(a)
+			attrs = append(attrs, fqn)
+		}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(attrs)))
+}
+
+// Excluded reports whether rel matches one of the manifest's exclude globs.
+func (m *Manifest) Excluded(rel string) bool {
+	for _, g := range m.Exclude {
+		if globMatch(g, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributesFor returns the sorted, de-duplicated attribute FQNs for the
+// slash-separated relative path rel, and whether any rule matched.
+func (m *Manifest) AttributesFor(rel string) ([]string, bool) {
+	seen := map[string]bool{}
+	matched := false
+	for _, r := range m.Rules {
+		if !globMatch(r.Match, rel) {
+			continue
+		}
+		matched = true
+		for _, a := range r.Attributes {
+			// Already validated by ParseManifest
+			fqn, _ := m.expandFQN(a)
+			seen[fqn] = true
+		}
+	}
+
+	attrs := make([]string, 0, len(seen))
+	for a := range seen {
+		attrs = append(attrs, a)
+	}
+	sort.Strings(attrs)
+	return attrs, matched
+}
+
+func globMatch(pattern, rel string) bool {
+	name := rel
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(rel)
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
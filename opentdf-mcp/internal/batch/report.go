@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+)
+
+// WriteTable prints one row per result followed by a summary line.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tFILE\tOUTPUT\tSIZE\tATTRIBUTES")
+	for _, r := range results {
+		detail := shortAttributes(r.Attributes)
+		if r.Error != "" {
+			detail = r.Error
+		}
+		size := "-"
+		if r.Status == StatusEncrypted || r.Status == StatusUnchanged {
+			size = tdf.HumanSize(r.PlaintextSize)
+		}
+		output := r.Output
+		if output == "" {
+			output = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Status, r.Path, output, size, detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, Summary(results))
+	return err
+}
+
+// Summary returns a one-line count of results by status.
+func Summary(results []Result) string {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	var parts []string
+	for _, s := range []string{StatusEncrypted, StatusUnchanged, StatusUnmatched, StatusExcluded, StatusFailed} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	if len(parts) == 0 {
+		return "no files found"
+	}
+	return fmt.Sprintf("%d files: %s", len(results), strings.Join(parts, ", "))
+}
+
+// Failed reports whether any result has StatusFailed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// shortAttributes renders FQNs without their namespace prefix, e.g.
+// "flight_id/value/RCH2532101", to keep table rows readable.
+func shortAttributes(attrs []string) string {
+	short := make([]string, len(attrs))
+	for i, a := range attrs {
+		if idx := strings.Index(a, "/attr/"); idx >= 0 {
+			a = a[idx+len("/attr/"):]
+		}
+		short[i] = a
+	}
+	return strings.Join(short, ", ")
+}
+
+// WriteDecryptTable prints one row per decrypt result followed by a summary
+// line.
+func WriteDecryptTable(w io.Writer, results []DecryptResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tFILE\tFORMAT\tOUTPUT\tSIZE\tDETAIL")
+	for _, r := range results {
+		format, output, size := "-", "-", "-"
+		if r.Format != "" {
+			format = tdf.Format(r.Format).String()
+		}
+		if r.Output != "" {
+			output = r.Output
+		}
+		if r.Status == StatusAllowed {
+			size = tdf.HumanSize(r.Size)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Status, r.Path, format, output, size, r.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, DecryptSummary(results))
+	return err
+}
+
+// DecryptSummary returns a one-line count of decrypt results by status.
+func DecryptSummary(results []DecryptResult) string {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	var parts []string
+	for _, s := range []string{StatusAllowed, string(tdf.KindDenied), string(tdf.KindAuth), string(tdf.KindIntegrity), string(tdf.KindMalformed), string(tdf.KindNetwork), string(tdf.KindOther)} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	if len(parts) == 0 {
+		return "no files found"
+	}
+	return fmt.Sprintf("%d files: %s", len(results), strings.Join(parts, ", "))
+}
@@ -0,0 +1,299 @@
+package csvtdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+)
+
+// RowColumn is the extra column that holds the sealed cells of each row in
+// row mode. The columns it covers keep their position with an empty value
+// and a header prefixed with tdf.SealedPrefix.
+const RowColumn = tdf.SealedPrefix + "row"
+
+// utf8BOM is kept on output when the input starts with it, as files
+// exported from Excel do.
+const utf8BOM = "\ufeff"
+
+// EncryptResult summarizes an Encrypt call.
+type EncryptResult struct {
+	Mode             string   `json:"mode"`
+	Rows             int      `json:"rows"`
+	SealedValues     int      `json:"sealedValues"`
+	Policies         int      `json:"policies"`
+	ClearColumns     []string `json:"clearColumns"`
+	EncryptedColumns []string `json:"encryptedColumns"`
+	// UnknownColumns are named in the rules but absent from the file.
+	UnknownColumns []string `json:"unknownColumns,omitempty"`
+}
+
+// DecryptResult summarizes a Decrypt call.
+type DecryptResult struct {
+	Rows int `json:"rows"`
+	// Opened and Redacted count cells, also in row mode.
+	Opened   int `json:"opened"`
+	Redacted int `json:"redacted"`
+}
+
+// Guard vets the text of a value before it is sealed with attrs, e.g.
+// against the classification markings it carries. location names the cell
+// or row. An error aborts Encrypt.
+type Guard func(location string, text []byte, attrs []string) error
+
+// Encrypt reads a CSV file with a header row from r and writes it to w with
+// every column not listed in rules.Clear sealed as inline nanoTDFs. A
+// non-nil guard is called for every value to seal.
+func Encrypt(sealer *tdf.Sealer, w io.Writer, r io.Reader, rules *Rules, guard Guard) (EncryptResult, error) {
+	cr := csv.NewReader(r)
+	header, bom, err := readHeader(cr)
+	if err != nil {
+		return EncryptResult{}, err
+	}
+
+	result := EncryptResult{Mode: rules.Mode, UnknownColumns: rules.unknownColumns(header)}
+	var sealedCols []int
+	for i, c := range header {
+		if strings.HasPrefix(c, tdf.SealedPrefix) {
+			return EncryptResult{}, fmt.Errorf("column %q is already encrypted", c)
+		}
+		if slices.Contains(rules.Clear, c) {
+			result.ClearColumns = append(result.ClearColumns, c)
+		} else {
+			result.EncryptedColumns = append(result.EncryptedColumns, c)
+			sealedCols = append(sealedCols, i)
+		}
+	}
+	if len(sealedCols) == 0 {
+		return EncryptResult{}, fmt.Errorf("every column is in the clear; nothing to encrypt")
+	}
+
+	out := slices.Clone(header)
+	if rules.Mode == ModeRow {
+		for _, i := range sealedCols {
+			out[i] = tdf.SealedPrefix + out[i]
+		}
+		out = append(out, RowColumn)
+	}
+	cw, err := writeHeader(w, out, bom)
+	if err != nil {
+		return EncryptResult{}, err
+	}
+
+	policies := map[string]bool{}
+	seal := func(location string, value []byte, attrs []string) (string, error) {
+		attrs = slices.Compact(slices.Sorted(slices.Values(attrs)))
+		if guard != nil {
+			if err := guard(location, value, attrs); err != nil {
+				return "", err
+			}
+		}
+		policies[strings.Join(attrs, "\n")] = true
+		result.SealedValues++
+		return sealer.Seal(value, attrs)
+	}
+
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return EncryptResult{}, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		result.Rows++
+
+		out := slices.Clone(row)
+		if rules.Mode == ModeRow {
+			var attrs []string
+			values := make([]string, 0, len(sealedCols))
+			for _, i := range sealedCols {
+				attrs = append(attrs, rules.attributesFor(header, row, header[i])...)
+				values = append(values, row[i])
+				out[i] = ""
+			}
+			if len(attrs) == 0 {
+				return EncryptResult{}, fmt.Errorf("row %d: no attributes apply; add top-level attributes or a rule for it", result.Rows)
+			}
+			record, err := encodeRecord(values)
+			if err != nil {
+				return EncryptResult{}, err
+			}
+			sealed, err := seal(fmt.Sprintf("row %d", result.Rows), record, attrs)
+			if err != nil {
+				return EncryptResult{}, fmt.Errorf("row %d: %w", result.Rows, err)
+			}
+			out = append(out, sealed)
+		} else {
+			for _, i := range sealedCols {
+				attrs := rules.attributesFor(header, row, header[i])
+				if len(attrs) == 0 {
+					return EncryptResult{}, fmt.Errorf("row %d column %s: no attributes apply; list the column under clear or add a rule for it", result.Rows, header[i])
+				}
+				if out[i], err = seal(fmt.Sprintf("row %d column %s", result.Rows, header[i]), []byte(row[i]), attrs); err != nil {
+					return EncryptResult{}, fmt.Errorf("row %d column %s: %w", result.Rows, header[i], err)
+				}
+			}
+		}
+		if err := cw.Write(out); err != nil {
+			return EncryptResult{}, fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return EncryptResult{}, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	result.Policies = len(policies)
+	return result, nil
+}
+
+// Decrypt rebuilds a CSV file written by Encrypt, in either mode. Cells the
+// caller is not entitled to are written as tdf.Redacted; any other failure
+// aborts the whole file.
+func Decrypt(ctx context.Context, opener *tdf.Opener, w io.Writer, r io.Reader) (DecryptResult, error) {
+	cr := csv.NewReader(r)
+	header, bom, err := readHeader(cr)
+	if err != nil {
+		return DecryptResult{}, err
+	}
+
+	// Row mode marks the columns it covers in the header
+	rowMode := len(header) > 0 && header[len(header)-1] == RowColumn
+	out := slices.Clone(header)
+	var sealedCols []int
+	if rowMode {
+		out = out[:len(out)-1]
+		for i, c := range out {
+			if name, ok := strings.CutPrefix(c, tdf.SealedPrefix); ok {
+				out[i] = name
+				sealedCols = append(sealedCols, i)
+			}
+		}
+	}
+	cw, err := writeHeader(w, out, bom)
+	if err != nil {
+		return DecryptResult{}, err
+	}
+
+	var result DecryptResult
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return DecryptResult{}, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		result.Rows++
+
+		if rowMode {
+			sealed := row[len(row)-1]
+			row = row[:len(row)-1]
+			values, ok, err := openRecord(ctx, opener, sealed, len(sealedCols))
+			if err != nil {
+				return DecryptResult{}, fmt.Errorf("row %d: %w", result.Rows, err)
+			}
+			for j, i := range sealedCols {
+				if ok {
+					row[i] = values[j]
+					result.Opened++
+				} else {
+					row[i] = tdf.Redacted
+					result.Redacted++
+				}
+			}
+		} else {
+			for i, cell := range row {
+				if !tdf.IsSealed(cell) {
+					continue
+				}
+				plaintext, ok, err := opener.Open(ctx, cell)
+				if err != nil {
+					return DecryptResult{}, fmt.Errorf("row %d column %s: %w", result.Rows, header[i], err)
+				}
+				if ok {
+					row[i] = string(plaintext)
+					result.Opened++
+				} else {
+					row[i] = tdf.Redacted
+					result.Redacted++
+				}
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return DecryptResult{}, fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return DecryptResult{}, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return result, nil
+}
+
+// openRecord opens a sealed row and checks it holds want values.
+func openRecord(ctx context.Context, opener *tdf.Opener, sealed string, want int) ([]string, bool, error) {
+	if !tdf.IsSealed(sealed) {
+		return nil, false, fmt.Errorf("%s is not an encrypted value", RowColumn)
+	}
+	plaintext, ok, err := opener.Open(ctx, sealed)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	values, err := csv.NewReader(bytes.NewReader(plaintext)).Read()
+	if errors.Is(err, io.EOF) {
+		// A row of one empty value encodes as a blank line
+		values, err = []string{""}, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse decrypted row: %w", err)
+	}
+	if len(values) != want {
+		return nil, false, fmt.Errorf("decrypted row has %d values but the header marks %d encrypted columns", len(values), want)
+	}
+	return values, true, nil
+}
+
+func readHeader(cr *csv.Reader) ([]string, bool, error) {
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, false, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	bom := strings.HasPrefix(header[0], utf8BOM)
+	header[0] = strings.TrimPrefix(header[0], utf8BOM)
+	return header, bom, nil
+}
+
+func writeHeader(w io.Writer, header []string, bom bool) (*csv.Writer, error) {
+	if bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return cw, nil
+}
+
+func encodeRecord(values []string) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(values); err != nil {
+		return nil, fmt.Errorf("failed to encode row: %w", err)
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
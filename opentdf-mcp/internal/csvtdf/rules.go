@@ -0,0 +1,180 @@
+// Package csvtdf encrypts CSV files cell by cell or row by row, leaving the
+// header and chosen columns readable, and rebuilds them on decrypt with the
+// cells the caller cannot open shown as [REDACTED].
+package csvtdf
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ModeCell encrypts every protected cell as its own nanoTDF.
+	ModeCell = "cell"
+	// ModeRow encrypts the protected cells of each row together.
+	ModeRow = "row"
+)
+
+// Rules decide which cells of a CSV file are encrypted and with which
+// attributes.
+//
+// Example (YAML; the equivalent JSON document is accepted as well):
+//
+//	namespace: https://demo.usaf.mil
+//	mode: cell
+//	clear: [Time_s, Event_Timestamp_Local, Flight_Phase]
+//	attributes: [flight_rch2532101/value/true]
+//	rules:
+//	  - column: System_Error_Code
+//	    attributes: [functional_maintenance/value/true]
+//	  - column: Flight_Phase
+//	    value: "Contact*"
+//	    attributes: [classification_secret/value/true]
+//
+// Every column not listed in Clear is encrypted. A cell's attributes are
+// the top-level Attributes, plus those of each rule naming its column,
+// plus those of each value rule matching its row. Every encrypted cell must
+// end up with at least one attribute.
+type Rules struct {
+	// Namespace is prepended to short attribute references such as
+	// "flight_id/value/RCH2532101".
+	Namespace string `yaml:"namespace" json:"namespace"`
+	// Mode is "cell" (default) or "row".
+	Mode string `yaml:"mode" json:"mode"`
+	// Clear lists the columns that are never encrypted.
+	Clear []string `yaml:"clear" json:"clear"`
+	// Attributes apply to every encrypted cell.
+	Attributes []string `yaml:"attributes" json:"attributes"`
+	Rules      []Rule   `yaml:"rules" json:"rules"`
+}
+
+// Rule adds Attributes to the encrypted cells of Column. With Value set it
+// instead adds them to every encrypted cell of the rows whose Column matches
+// the Value glob, which is how a row is tagged by its content.
+type Rule struct {
+	Column     string   `yaml:"column" json:"column"`
+	Value      string   `yaml:"value" json:"value"`
+	Attributes []string `yaml:"attributes" json:"attributes"`
+}
+
+// LoadRules reads YAML or JSON rules from path.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// ParseRules parses YAML or JSON rules, validates them and expands short
+// attribute references to full FQNs.
+func ParseRules(data []byte) (*Rules, error) {
+	var r Rules
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	switch r.Mode {
+	case "":
+		r.Mode = ModeCell
+	case ModeCell, ModeRow:
+	default:
+		return nil, fmt.Errorf("unsupported mode %q (expected %q or %q)", r.Mode, ModeCell, ModeRow)
+	}
+
+	var err error
+	if r.Attributes, err = r.expand(r.Attributes); err != nil {
+		return nil, err
+	}
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Column == "" {
+			return nil, fmt.Errorf("rule %d: column is required", i+1)
+		}
+		if len(rule.Attributes) == 0 {
+			return nil, fmt.Errorf("rule %d: attributes are required", i+1)
+		}
+		if _, err := path.Match(rule.Value, ""); err != nil {
+			return nil, fmt.Errorf("rule %d: invalid value glob %q: %w", i+1, rule.Value, err)
+		}
+		if rule.Attributes, err = r.expand(rule.Attributes); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	if len(r.Attributes) == 0 && len(r.Rules) == 0 {
+		return nil, fmt.Errorf("rules give no attributes; set attributes or add a rule")
+	}
+	return &r, nil
+}
+
+// expand turns short attribute references into full FQNs, normalized as the
+// platform stores them.
+func (r *Rules) expand(attrs []string) ([]string, error) {
+	out := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		fqn, err := tdf.ExpandAttribute(r.Namespace, a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fqn)
+	}
+	return tdf.NormalizeAttributes(out)
+}
+
+// AttributeValues returns every attribute FQN the rules can apply, for
+// checking them against the platform before anything is sealed.
+func (r *Rules) AttributeValues() []string {
+	attrs := slices.Clone(r.Attributes)
+	for _, rule := range r.Rules {
+		attrs = append(attrs, rule.Attributes...)
+	}
+	return slices.Compact(slices.Sorted(slices.Values(attrs)))
+}
+
+// unknownColumns lists the columns named in the rules that are not in
+// header, so a rules file shared by several CSV layouts can be checked for
+// typos.
+func (r *Rules) unknownColumns(header []string) []string {
+	var unknown []string
+	add := func(c string) {
+		if !slices.Contains(header, c) && !slices.Contains(unknown, c) {
+			unknown = append(unknown, c)
+		}
+	}
+	for _, c := range r.Clear {
+		add(c)
+	}
+	for _, rule := range r.Rules {
+		add(rule.Column)
+	}
+	return unknown
+}
+
+// attributesFor returns the attributes of the encrypted cell in column
+// col of row. The row-wide part is the same for every cell, so in row mode
+// callers merge the results for all encrypted columns.
+func (r *Rules) attributesFor(header, row []string, col string) []string {
+	attrs := slices.Clone(r.Attributes)
+	for _, rule := range r.Rules {
+		if rule.Value == "" {
+			if rule.Column == col {
+				attrs = append(attrs, rule.Attributes...)
+			}
+			continue
+		}
+		i := slices.Index(header, rule.Column)
+		if i < 0 || i >= len(row) {
+			continue
+		}
+		if ok, _ := path.Match(rule.Value, row[i]); ok {
+			attrs = append(attrs, rule.Attributes...)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,378 @@
+// Package doctdf encrypts selected fields of JSON and YAML documents in
+// place. Each selected value is replaced by an inline nanoTDF string, so the
+// document stays valid and its structure readable; decrypt opens whatever
+// the caller is entitled to and marks the rest [REDACTED].
+package doctdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+// Syntax is the serialization of a document.
+type Syntax string
+
+const (
+	SyntaxJSON Syntax = "json"
+	SyntaxYAML Syntax = "yaml"
+)
+
+// DetectSyntax picks JSON or YAML from the file extension of name, falling
+// back to the content: documents starting with { or [ are JSON.
+func DetectSyntax(name string, data []byte) Syntax {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return SyntaxJSON
+	case ".yaml", ".yml":
+		return SyntaxYAML
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return SyntaxJSON
+	}
+	return SyntaxYAML
+}
+
+// Field selects the values to encrypt with a JSONPath and the attributes
+// they are encrypted with.
+type Field struct {
+	Path       string   `yaml:"path" json:"path"`
+	Attributes []string `yaml:"attributes" json:"attributes"`
+}
+
+// ParseField parses the command line form "PATH=FQN[,FQN...]". The last "="
+// separates the path from the attributes, since FQNs never contain one.
+func ParseField(s string) (Field, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return Field{}, fmt.Errorf("invalid field %q: expected PATH=FQN[,FQN...]", s)
+	}
+	var attrs []string
+	for _, a := range strings.Split(s[i+1:], ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return Field{Path: strings.TrimSpace(s[:i]), Attributes: attrs}, nil
+}
+
+// LoadFields reads a YAML or JSON list of fields from path.
+func LoadFields(path string) ([]Field, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fields: %w", err)
+	}
+	var fields []Field
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse fields: %w", err)
+	}
+	return fields, nil
+}
+
+// SealedField is one value replaced by Encrypt.
+type SealedField struct {
+	Path       string   `json:"path"`
+	Attributes []string `json:"attributes"`
+}
+
+// EncryptResult reports what Encrypt sealed.
+type EncryptResult struct {
+	Syntax Syntax        `json:"syntax"`
+	Sealed []SealedField `json:"sealed"`
+	// Unmatched lists selectors that matched nothing in the document.
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// DecryptResult reports which sealed values Decrypt could open.
+type DecryptResult struct {
+	Syntax   Syntax   `json:"syntax"`
+	Opened   []string `json:"opened"`
+	Redacted []string `json:"redacted"`
+}
+
+// Guard vets the text of a selected value before it is sealed with attrs,
+// e.g. against the classification markings it carries. An error aborts
+// Encrypt before anything is sealed.
+type Guard func(path string, text []byte, attrs []string) error
+
+// Encrypt replaces every value selected by fields with an inline nanoTDF
+// string. attrs are added to every field's attributes. A value selected by
+// several fields gets all their attributes; a value inside another selected
+// value is sealed as part of it, so the outer value carries both sets. A
+// non-nil guard is called for every value to seal.
+func Encrypt(sealer *tdf.Sealer, data []byte, syntax Syntax, fields []Field, attrs []string, guard Guard) ([]byte, EncryptResult, error) {
+	if len(fields) == 0 {
+		return nil, EncryptResult{}, fmt.Errorf("no fields selected for encryption")
+	}
+	root, err := parse(data)
+	if err != nil {
+		return nil, EncryptResult{}, err
+	}
+
+	result := EncryptResult{Syntax: syntax}
+	selected := map[*yaml.Node]*SealedField{}
+	var order []match
+	for _, f := range fields {
+		p, err := ParsePath(f.Path)
+		if err != nil {
+			return nil, EncryptResult{}, err
+		}
+		fieldAttrs := append(slices.Clone(attrs), f.Attributes...)
+		if len(fieldAttrs) == 0 {
+			return nil, EncryptResult{}, fmt.Errorf("field %s has no attributes", f.Path)
+		}
+		matches := p.selectNodes(root)
+		if len(matches) == 0 {
+			result.Unmatched = append(result.Unmatched, f.Path)
+		}
+		for _, m := range matches {
+			if m.node == root {
+				return nil, EncryptResult{}, fmt.Errorf("field %s selects the whole document; encrypt it as a TDF instead", f.Path)
+			}
+			sf, ok := selected[m.node]
+			if !ok {
+				sf = &SealedField{Path: m.location()}
+				selected[m.node] = sf
+				order = append(order, m)
+			}
+			sf.Attributes = append(sf.Attributes, fieldAttrs...)
+		}
+	}
+
+	if len(order) == 0 {
+		return nil, EncryptResult{}, fmt.Errorf("no field selector matched the document")
+	}
+
+	// Fold values nested in another selected value into their ancestor
+	var outer []match
+	for _, m := range order {
+		if a := ancestor(m, order); a != nil {
+			selected[a.node].Attributes = append(selected[a.node].Attributes, selected[m.node].Attributes...)
+			continue
+		}
+		outer = append(outer, m)
+	}
+	for _, m := range outer {
+		sf := selected[m.node]
+		sf.Attributes = slices.Compact(slices.Sorted(slices.Values(sf.Attributes)))
+		if guard != nil {
+			if err := guard(sf.Path, scalarText(m.node), sf.Attributes); err != nil {
+				return nil, EncryptResult{}, fmt.Errorf("%s: %w", sf.Path, err)
+			}
+		}
+	}
+
+	for _, m := range outer {
+		sf := selected[m.node]
+		plaintext, err := encodeJSON(m.node)
+		if err != nil {
+			return nil, EncryptResult{}, fmt.Errorf("%s: %w", sf.Path, err)
+		}
+		sealed, err := sealer.Seal(plaintext, sf.Attributes)
+		if err != nil {
+			return nil, EncryptResult{}, fmt.Errorf("%s: %w", sf.Path, err)
+		}
+		*m.node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: sealed}
+		result.Sealed = append(result.Sealed, *sf)
+	}
+
+	out, err := format(root, syntax)
+	if err != nil {
+		return nil, EncryptResult{}, err
+	}
+	return out, result, nil
+}
+
+// Decrypt opens every sealed string value in the document. Values the
+// caller is not entitled to become tdf.Redacted; any other failure aborts.
+func Decrypt(ctx context.Context, opener *tdf.Opener, data []byte, syntax Syntax) ([]byte, DecryptResult, error) {
+	root, err := parse(data)
+	if err != nil {
+		return nil, DecryptResult{}, err
+	}
+
+	result := DecryptResult{Syntax: syntax, Opened: []string{}, Redacted: []string{}}
+	var sealed []match
+	walk(match{node: root}, func(m match) {
+		if m.node.Kind == yaml.ScalarNode && m.node.ShortTag() == "!!str" && tdf.IsSealed(m.node.Value) {
+			sealed = append(sealed, m)
+		}
+	})
+	for _, m := range sealed {
+		plaintext, ok, err := opener.Open(ctx, m.node.Value)
+		if err != nil {
+			return nil, DecryptResult{}, fmt.Errorf("%s: %w", m.location(), err)
+		}
+		if !ok {
+			*m.node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tdf.Redacted}
+			result.Redacted = append(result.Redacted, m.location())
+			continue
+		}
+		value, err := parse(plaintext)
+		if err != nil {
+			return nil, DecryptResult{}, fmt.Errorf("%s: decrypted value is not valid JSON: %w", m.location(), err)
+		}
+		if syntax == SyntaxYAML {
+			clearStyle(value)
+		}
+		*m.node = *value
+		result.Opened = append(result.Opened, m.location())
+	}
+
+	out, err := format(root, syntax)
+	if err != nil {
+		return nil, DecryptResult{}, err
+	}
+	return out, result, nil
+}
+
+// IsSealedDocument reports whether data is a JSON or YAML object or array
+// holding values sealed by Encrypt, as opposed to arbitrary plaintext (such
+// as a CSV file from csvtdf, which parses as a YAML string).
+func IsSealedDocument(data []byte) bool {
+	if !bytes.Contains(data, []byte(tdf.SealedPrefix)) {
+		return false
+	}
+	root, err := parse(data)
+	return err == nil && (root.Kind == yaml.MappingNode || root.Kind == yaml.SequenceNode)
+}
+
+// scalarText joins the scalar values at and below n, one per line, so
+// markings inside strings are scanned as the text a reader would see rather
+// than as escaped JSON.
+func scalarText(n *yaml.Node) []byte {
+	var b bytes.Buffer
+	walk(match{node: n}, func(m match) {
+		if m.node.Kind == yaml.ScalarNode {
+			b.WriteString(m.node.Value)
+			b.WriteByte('\n')
+		}
+	})
+	return b.Bytes()
+}
+
+// ancestor returns the outermost match in all that contains m, if any.
+func ancestor(m match, all []match) *match {
+	var best *match
+	for i, a := range all {
+		if len(a.path) < len(m.path) && slices.Equal(a.path, m.path[:len(a.path)]) {
+			if best == nil || len(a.path) < len(best.path) {
+				best = &all[i]
+			}
+		}
+	}
+	return best
+}
+
+// parse decodes a single JSON or YAML document, returning its root value.
+func parse(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	return doc.Content[0], nil
+}
+
+func format(root *yaml.Node, syntax Syntax) ([]byte, error) {
+	if syntax == SyntaxJSON {
+		compact, err := encodeJSON(root)
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, compact, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to format JSON: %w", err)
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// encodeJSON writes node as compact JSON, keeping mapping key order.
+func encodeJSON(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		var v any = node.Value
+		if node.ShortTag() != "!!str" {
+			if err := node.Decode(&v); err != nil {
+				return fmt.Errorf("failed to decode %q: %w", node.Value, err)
+			}
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("value %q cannot be represented in JSON: %w", node.Value, err)
+		}
+		buf.Write(b)
+	default:
+		return fmt.Errorf("unsupported YAML node kind %d", node.Kind)
+	}
+	return nil
+}
+
+// clearStyle resets the flow and quoting styles a value picked up from its
+// JSON encoding, so it is written back in block style.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, c := range node.Content {
+		clearStyle(c)
+	}
+}
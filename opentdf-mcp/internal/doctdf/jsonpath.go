@@ -0,0 +1,180 @@
+package doctdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// step is one segment of a compiled JSONPath.
+type step struct {
+	// key selects a mapping member; "*" with wildcard set selects every
+	// member or element.
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+	// recursive applies the step to the current node and every descendant
+	// ("..").
+	recursive bool
+}
+
+// Path is a compiled JSONPath selector. The supported subset is the root
+// "$", member access (".name", "['name']"), array indices ("[0]", "[-1]"),
+// wildcards (".*", "[*]") and recursive descent ("..name", "..*").
+// Filter and script expressions are not supported.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// String returns the selector as written.
+func (p Path) String() string {
+	return p.expr
+}
+
+// ParsePath compiles a JSONPath selector.
+func ParsePath(expr string) (Path, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return Path{}, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+	s = s[1:]
+
+	p := Path{expr: expr}
+	for s != "" {
+		recursive := false
+		switch {
+		case strings.HasPrefix(s, ".."):
+			recursive = true
+			s = s[2:]
+			if strings.HasPrefix(s, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(s, "."):
+			s = strings.TrimPrefix(s, ".")
+			name := s
+			if i := strings.IndexAny(s, ".["); i >= 0 {
+				name = s[:i]
+			}
+			if name == "" {
+				return Path{}, fmt.Errorf("invalid JSONPath %q: empty member name", expr)
+			}
+			p.steps = append(p.steps, step{key: name, wildcard: name == "*", recursive: recursive})
+			s = s[len(name):]
+			continue
+		case !strings.HasPrefix(s, "["):
+			return Path{}, fmt.Errorf("invalid JSONPath %q: unexpected %q", expr, s)
+		}
+
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return Path{}, fmt.Errorf("invalid JSONPath %q: missing ]", expr)
+		}
+		inner := strings.TrimSpace(s[1:end])
+		st := step{recursive: recursive}
+		switch {
+		case inner == "*":
+			st.wildcard = true
+		case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+			st.key = inner[1 : len(inner)-1]
+		default:
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return Path{}, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expr, inner)
+			}
+			st.index, st.isIndex = n, true
+		}
+		p.steps = append(p.steps, st)
+		s = s[end+1:]
+	}
+	return p, nil
+}
+
+// match is a node selected by a Path, with its location in the document.
+type match struct {
+	node *yaml.Node
+	path []string
+}
+
+// selectNodes returns the nodes under root that p selects.
+func (p Path) selectNodes(root *yaml.Node) []match {
+	current := []match{{node: root}}
+	for _, st := range p.steps {
+		var next []match
+		for _, m := range current {
+			if st.recursive {
+				walk(m, func(d match) { next = append(next, st.apply(d)...) })
+			} else {
+				next = append(next, st.apply(m)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// apply returns the children of m selected by st.
+func (st step) apply(m match) []match {
+	var out []match
+	switch m.node.Kind {
+	case yaml.MappingNode:
+		if st.isIndex {
+			return nil
+		}
+		for i := 0; i+1 < len(m.node.Content); i += 2 {
+			key := m.node.Content[i].Value
+			if st.wildcard || key == st.key {
+				out = append(out, m.child(memberPath(key), m.node.Content[i+1]))
+			}
+		}
+	case yaml.SequenceNode:
+		switch {
+		case st.wildcard:
+			for i, c := range m.node.Content {
+				out = append(out, m.child(fmt.Sprintf("[%d]", i), c))
+			}
+		case st.isIndex:
+			i := st.index
+			if i < 0 {
+				i += len(m.node.Content)
+			}
+			if i >= 0 && i < len(m.node.Content) {
+				out = append(out, m.child(fmt.Sprintf("[%d]", i), m.node.Content[i]))
+			}
+		}
+	}
+	return out
+}
+
+// walk calls fn for m and every node below it, parents first.
+func walk(m match, fn func(match)) {
+	fn(m)
+	for _, c := range (step{wildcard: true}).apply(m) {
+		walk(c, fn)
+	}
+}
+
+func (m match) child(segment string, node *yaml.Node) match {
+	path := make([]string, len(m.path), len(m.path)+1)
+	copy(path, m.path)
+	return match{node: node, path: append(path, segment)}
+}
+
+// location formats the concrete path of m, e.g. "$.crew[0].name".
+func (m match) location() string {
+	return "$" + strings.Join(m.path, "")
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func memberPath(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	return "['" + strings.ReplaceAll(key, "'", `\'`) + "']"
+}
@@ -0,0 +1,193 @@
+package memotdf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+// Derivation reports the attributes Derive read from memo frontmatter and
+// the values it found.
+type Derivation struct {
+	Classification string   `json:"classification"`
+	Flights        []string `json:"flights,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Attributes     []string `json:"attributes"`
+}
+
+// DeriveFile reads memo markdown from path and derives its attributes.
+func DeriveFile(path string, table *Table) (Derivation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Derivation{}, fmt.Errorf("failed to read memo source: %w", err)
+	}
+	return Derive(data, table)
+}
+
+// Derive returns the attributes a memo, or a document rendered from it,
+// must be encrypted with: those of its classification banner, of every
+// flight identifier referenced anywhere in the frontmatter, and of each
+// functional tag under "tags". A banner, flight or tag the table cannot map
+// is an error, so nothing in the frontmatter is silently left out of the
+// policy.
+func Derive(data []byte, table *Table) (Derivation, error) {
+	m, err := parse(data)
+	if err != nil {
+		return Derivation{}, err
+	}
+
+	d := Derivation{Classification: m.classification}
+	level, ok := bannerLevel(m.classification)
+	if !ok {
+		return Derivation{}, fmt.Errorf("unrecognized classification %q in the memo frontmatter", m.classification)
+	}
+	attrs, ok := table.Classifications[level]
+	if !ok {
+		return Derivation{}, fmt.Errorf("no attributes for classification %q (%s); add it to the marking table", m.classification, level)
+	}
+	attrs = slices.Clone(attrs)
+
+	// Flights may be referenced in the subject, references or any other
+	// field, so every string value is searched
+	var scalars []string
+	collectScalars(m.front, &scalars)
+	for _, s := range scalars {
+		for _, flight := range table.flightPattern.FindAllString(s, -1) {
+			flight = strings.ToUpper(flight)
+			if !slices.Contains(d.Flights, flight) {
+				d.Flights = append(d.Flights, flight)
+			}
+		}
+	}
+	for _, flight := range d.Flights {
+		if mapped, ok := table.Flights[flight]; ok {
+			attrs = append(attrs, mapped...)
+			continue
+		}
+		fqn, err := table.flightFQN(flight)
+		if err != nil {
+			return Derivation{}, err
+		}
+		attrs = append(attrs, fqn)
+	}
+
+	var fields struct {
+		Tags []string `yaml:"tags"`
+	}
+	if err := m.front.Decode(&fields); err != nil {
+		return Derivation{}, fmt.Errorf("memo frontmatter tags must be a list: %w", err)
+	}
+	for _, tag := range fields.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		mapped, ok := table.Tags[tag]
+		if !ok {
+			return Derivation{}, fmt.Errorf("no attributes for tag %q in the memo frontmatter; add it to the marking table", tag)
+		}
+		d.Tags = append(d.Tags, tag)
+		attrs = append(attrs, mapped...)
+	}
+
+	d.Attributes = normalize(attrs)
+	return d, nil
+}
+
+// Check compares explicitly requested attributes with the derived ones and
+// describes any disagreement. No explicit attributes always agree.
+func (d Derivation) Check(explicit []string) error {
+	if len(explicit) == 0 {
+		return nil
+	}
+	explicit = normalize(explicit)
+	var missing, extra []string
+	for _, a := range d.Attributes {
+		if !containsFold(explicit, a) {
+			missing = append(missing, a)
+		}
+	}
+	for _, a := range explicit {
+		if !containsFold(d.Attributes, a) {
+			extra = append(extra, a)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil
+	}
+
+	var reasons []string
+	if len(missing) > 0 {
+		reasons = append(reasons, "the frontmatter requires "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		reasons = append(reasons, "the attributes add "+strings.Join(extra, ", "))
+	}
+	return fmt.Errorf("attributes disagree with the memo frontmatter (classification %q): %s", d.Classification, strings.Join(reasons, "; "))
+}
+
+// CheckRendition verifies that the file at path is the memo source itself
+// or a PDF, presumably rendered from it; the frontmatter describes nothing
+// else.
+func CheckRendition(path, source string) error {
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("failed to read memo source: %w", err)
+	}
+	if os.SameFile(pathInfo, sourceInfo) {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer f.Close()
+	magic := make([]byte, len(pdfMagic))
+	if _, err := io.ReadFull(f, magic); err != nil || string(magic) != pdfMagic {
+		return fmt.Errorf("%s is neither the memo source %s nor a rendered PDF", path, source)
+	}
+	return nil
+}
+
+// pdfMagic starts every PDF file.
+const pdfMagic = "%PDF-"
+
+// flightFQN applies FlightAttribute to a flight identifier.
+func (t *Table) flightFQN(flight string) (string, error) {
+	return tdf.ExpandAttribute(t.Namespace, strings.ReplaceAll(t.FlightAttribute, "{flight}", flight))
+}
+
+// collectScalars appends the values of every scalar below node.
+func collectScalars(node *yaml.Node, out *[]string) {
+	if node.Kind == yaml.ScalarNode {
+		*out = append(*out, node.Value)
+	}
+	for _, c := range node.Content {
+		collectScalars(c, out)
+	}
+}
+
+// normalize trims, sorts and deduplicates attribute FQNs.
+func normalize(attrs []string) []string {
+	out := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		if !containsFold(out, a) {
+			out = append(out, strings.TrimSpace(a))
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// containsFold reports whether attrs holds a, ignoring case as the platform
+// does for FQNs.
+func containsFold(attrs []string, a string) bool {
+	return slices.ContainsFunc(attrs, func(b string) bool { return strings.EqualFold(a, b) })
+}
@@ -0,0 +1,109 @@
+package memotdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxGuardScan bounds how much plaintext ScanMarkings reads; banners sit at
+// the top and bottom of a page, so this covers any text document.
+const maxGuardScan = 64 << 20
+
+// bannerMarking matches a classification banner such as
+// "TOP SECRET//FICTIONAL" anywhere in a line, frontmatter included.
+var bannerMarking = regexp.MustCompile(`\b(TOP SECRET|SECRET|CONFIDENTIAL)//`)
+
+// Marking is a classification marking found in plaintext.
+type Marking struct {
+	// Line is the line of the marking, counting from 1.
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+	Level string `json:"level"`
+}
+
+// ScanMarkings finds the classification banners and portion markings in
+// the text read from r, reporting the first marking of each level.
+func ScanMarkings(r io.Reader) ([]Marking, error) {
+	var found []Marking
+	add := func(line int, text, level string) {
+		if !slices.ContainsFunc(found, func(m Marking) bool { return m.Level == level }) {
+			found = append(found, Marking{Line: line, Text: text, Level: level})
+		}
+	}
+
+	br := bufio.NewReader(io.LimitReader(r, maxGuardScan))
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			trimmed := strings.TrimSpace(line)
+			for _, m := range bannerMarking.FindAllStringSubmatch(line, -1) {
+				add(n, m[0], bannerLevels[m[1]])
+			}
+			// A banner line may also carry just the level
+			if level, ok := bannerLevels[trimmed]; ok && level != "U" && trimmed != "" {
+				add(n, trimmed, level)
+			}
+			rest := strings.TrimPrefix(trimmed, strings.TrimSpace(listItem.FindString(trimmed)))
+			// A bare "(C)" is more likely a copyright sign than a marking
+			if m := portionMarking.FindStringSubmatch(strings.TrimSpace(rest)); m != nil && m[1] != "C" {
+				add(n, m[0], m[2])
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plaintext: %w", err)
+		}
+	}
+	return found, nil
+}
+
+// GuardError reports plaintext marked at a level its attributes do not
+// protect.
+type GuardError struct {
+	Marking Marking
+	// Expected are the attributes of the lowest level that would cover the
+	// marking.
+	Expected []string
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("plaintext is marked %s (line %d: %q) but the attributes do not include %s",
+		e.Marking.Level, e.Marking.Line, e.Marking.Text, strings.Join(e.Expected, ", "))
+}
+
+// CheckMarkings returns a *GuardError for the highest marking that attrs
+// do not cover. A marking is covered when attrs hold every classification
+// attribute of its level, or of a higher level; levels the table maps to
+// nothing, such as U, need no attributes.
+func (t *Table) CheckMarkings(markings []Marking, attrs []string) error {
+	slices.SortFunc(markings, func(a, b Marking) int {
+		return slices.Index(levels, b.Level) - slices.Index(levels, a.Level)
+	})
+	for _, m := range markings {
+		expected := t.Classifications[m.Level]
+		if len(expected) == 0 {
+			continue
+		}
+		var covered bool
+		for _, level := range levels[slices.Index(levels, m.Level):] {
+			required := t.Classifications[level]
+			if len(required) == 0 {
+				continue
+			}
+			covered = !slices.ContainsFunc(required, func(a string) bool { return !containsFold(attrs, a) })
+			if covered {
+				break
+			}
+		}
+		if !covered {
+			return &GuardError{Marking: m, Expected: expected}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package memotdf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckMarkings(t *testing.T) {
+	const (
+		flight = "https://demo.usaf.mil/attr/flight_id/value/rch2532101"
+		secret = "https://demo.usaf.mil/attr/classification/value/secret-fictional"
+		ts     = "https://demo.usaf.mil/attr/classification/value/top-secret-fictional"
+	)
+	tests := []struct {
+		name    string
+		text    string
+		attrs   []string
+		refused string
+	}{
+		{name: "unclassified portion", text: "(U) Routine paragraph.", attrs: []string{flight}},
+		{name: "CUI portion", text: "(CUI) Routine paragraph.", attrs: []string{flight}},
+		{name: "unmarked", text: "Routine paragraph.", attrs: nil},
+		{name: "secret portion", text: "(S) Tanker track.", attrs: []string{flight}, refused: "S"},
+		{name: "secret covered", text: "(S) Tanker track.", attrs: []string{secret}},
+		{name: "secret covered by higher", text: "(S) Tanker track.", attrs: []string{ts}},
+		{name: "top secret banner", text: "TOP SECRET//FICTIONAL\n(U) Cover.", attrs: []string{secret}, refused: "TS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markings, err := ScanMarkings(strings.NewReader(tt.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = DefaultTable().CheckMarkings(markings, tt.attrs)
+			if tt.refused == "" {
+				if err != nil {
+					t.Fatalf("CheckMarkings() = %v, want nil", err)
+				}
+				return
+			}
+			var guardErr *GuardError
+			if !errors.As(err, &guardErr) || guardErr.Marking.Level != tt.refused {
+				t.Fatalf("CheckMarkings() = %v, want a refusal of %s", err, tt.refused)
+			}
+		})
+	}
+}
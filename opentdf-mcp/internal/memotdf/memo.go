@@ -0,0 +1,289 @@
+package memotdf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// listItem matches the bullet or number that opens a list item.
+	listItem = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+	// portionMarking matches a portion marking such as "(U)", "(S//NF)" or
+	// "(TS//SCI//FICTIONAL)" at the start of a portion.
+	portionMarking = regexp.MustCompile(`^\(((TS|S|C|U|CUI)(?://[A-Z0-9][A-Z0-9 -]*)*)\)`)
+)
+
+// levels orders classification levels from lowest to highest.
+var levels = []string{"U", "CUI", "C", "S", "TS"}
+
+// bannerLevels maps the first word group of a classification banner to its
+// level.
+var bannerLevels = map[string]string{
+	"":             "U",
+	"UNCLASSIFIED": "U",
+	"CUI":          "CUI",
+	"CONFIDENTIAL": "C",
+	"SECRET":       "S",
+	"TOP SECRET":   "TS",
+}
+
+// Portion identifies one paragraph or list item of the memo body.
+type Portion struct {
+	// Line is the first line of the portion in the input, counting from 1.
+	Line int `json:"line"`
+	// Marking is the portion marking without parentheses, e.g. "S//NF";
+	// empty for an unmarked portion.
+	Marking    string   `json:"marking,omitempty"`
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+// EncryptResult reports what Encrypt sealed.
+type EncryptResult struct {
+	// Classification is the banner from the frontmatter.
+	Classification string    `json:"classification"`
+	Sealed         []Portion `json:"sealed"`
+	// Clear counts the portions left readable.
+	Clear    int      `json:"clear"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Attributes returns every attribute the sealed portions were encrypted
+// with, sorted and without duplicates.
+func (r EncryptResult) Attributes() []string {
+	var attrs []string
+	for _, p := range r.Sealed {
+		attrs = append(attrs, p.Attributes...)
+	}
+	return slices.Compact(slices.Sorted(slices.Values(attrs)))
+}
+
+// DecryptResult reports which portions Decrypt could open.
+type DecryptResult struct {
+	Classification string    `json:"classification"`
+	Opened         []Portion `json:"opened"`
+	Redacted       []Portion `json:"redacted"`
+}
+
+// memo is memo markdown split into frontmatter and body portions.
+type memo struct {
+	lines          []string
+	front          *yaml.Node
+	classification string
+	portions       []portion
+}
+
+// portion spans lines[start:end]. head is the list marker and marking the
+// portion starts with; text is the rest of the portion, continuation lines
+// included.
+type portion struct {
+	start, end int
+	head       string
+	marking    string
+	level      string
+	text       string
+}
+
+// Encrypt replaces every classified portion of the memo with an inline
+// nanoTDF under the attributes table gives its marking. The frontmatter,
+// blank lines and the markings themselves stay in the clear.
+func Encrypt(sealer *tdf.Sealer, data []byte, table *Table) ([]byte, EncryptResult, error) {
+	m, err := parse(data)
+	if err != nil {
+		return nil, EncryptResult{}, err
+	}
+
+	result := EncryptResult{Classification: m.classification, Sealed: []Portion{}}
+	banner, ok := bannerLevel(m.classification)
+	if !ok {
+		// An unknown banner is not compared against the portions
+		banner = levels[len(levels)-1]
+	}
+	replaced := map[int]string{}
+	for _, p := range m.portions {
+		if tdf.IsSealed(strings.TrimSpace(p.text)) {
+			return nil, EncryptResult{}, fmt.Errorf("line %d: portion is already encrypted", p.start+1)
+		}
+		level := p.level
+		if level == "" {
+			if table.Unmarked == "" {
+				return nil, EncryptResult{}, fmt.Errorf("line %d: portion has no marking; mark it, e.g. \"(U)\", or set an unmarked level", p.start+1)
+			}
+			level = table.Unmarked
+		}
+		if slices.Index(levels, level) > slices.Index(levels, banner) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("line %d: portion marked (%s) is above the memo classification %q", p.start+1, level, m.classification))
+		}
+
+		attrs, err := table.attributesFor(level)
+		if err != nil {
+			return nil, EncryptResult{}, fmt.Errorf("line %d: %w", p.start+1, err)
+		}
+		if len(attrs) == 0 {
+			result.Clear++
+			continue
+		}
+		sealed, err := sealer.Seal([]byte(p.text), attrs)
+		if err != nil {
+			return nil, EncryptResult{}, fmt.Errorf("line %d: %w", p.start+1, err)
+		}
+		replaced[p.start] = p.head + p.separator() + sealed
+		result.Sealed = append(result.Sealed, Portion{Line: p.start + 1, Marking: p.marking, Attributes: slices.Sorted(slices.Values(attrs))})
+	}
+	if len(result.Sealed) == 0 {
+		return nil, EncryptResult{}, fmt.Errorf("no portion needs encryption; every portion is in the clear")
+	}
+	return m.render(replaced), result, nil
+}
+
+// Decrypt opens every sealed portion of a memo written by Encrypt. Portions
+// the caller is not entitled to keep their marking and show tdf.Redacted;
+// any other failure aborts.
+func Decrypt(ctx context.Context, opener *tdf.Opener, data []byte) ([]byte, DecryptResult, error) {
+	m, err := parse(data)
+	if err != nil {
+		return nil, DecryptResult{}, err
+	}
+
+	result := DecryptResult{Classification: m.classification, Opened: []Portion{}, Redacted: []Portion{}}
+	replaced := map[int]string{}
+	for _, p := range m.portions {
+		sealed := strings.TrimSpace(p.text)
+		if !tdf.IsSealed(sealed) {
+			continue
+		}
+		plaintext, ok, err := opener.Open(ctx, sealed)
+		if err != nil {
+			return nil, DecryptResult{}, fmt.Errorf("line %d: %w", p.start+1, err)
+		}
+		info := Portion{Line: p.start + 1, Marking: p.marking}
+		if !ok {
+			replaced[p.start] = p.head + p.separator() + tdf.Redacted
+			result.Redacted = append(result.Redacted, info)
+			continue
+		}
+		replaced[p.start] = p.head + string(plaintext)
+		result.Opened = append(result.Opened, info)
+	}
+	return m.render(replaced), result, nil
+}
+
+// IsSealedMemo reports whether data is memo markdown with portions sealed
+// by Encrypt.
+func IsSealedMemo(data []byte) bool {
+	if !strings.Contains(string(data), tdf.SealedPrefix) {
+		return false
+	}
+	m, err := parse(data)
+	if err != nil {
+		return false
+	}
+	for _, p := range m.portions {
+		if tdf.IsSealed(strings.TrimSpace(p.text)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse splits memo markdown into its frontmatter, which must be present,
+// and the portions of its body.
+func parse(data []byte) (*memo, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return nil, fmt.Errorf("not a memo: the markdown does not start with --- frontmatter")
+	}
+	end := slices.IndexFunc(lines[1:], func(l string) bool { return strings.TrimSpace(l) == "---" })
+	if end < 0 {
+		return nil, fmt.Errorf("not a memo: the frontmatter is not closed by ---")
+	}
+	end++
+
+	var front yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &front); err != nil {
+		return nil, fmt.Errorf("failed to parse memo frontmatter: %w", err)
+	}
+	var fields struct {
+		Classification string `yaml:"classification"`
+	}
+	if err := front.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to parse memo frontmatter: %w", err)
+	}
+
+	m := &memo{lines: lines, front: &front, classification: strings.TrimSpace(fields.Classification)}
+	var current *portion
+	for i := end + 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		prefix := listItem.FindString(line)
+		if prefix == "" {
+			prefix = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		}
+		rest := line[len(prefix):]
+		marking := portionMarking.FindStringSubmatch(rest)
+
+		// A list item or a marked line opens a new portion; other lines
+		// continue the current paragraph
+		if current != nil && marking == nil && listItem.FindString(line) == "" {
+			current.text += "\n" + line
+			current.end = i + 1
+			continue
+		}
+		m.portions = append(m.portions, portion{start: i, end: i + 1, head: prefix, text: rest})
+		current = &m.portions[len(m.portions)-1]
+		if marking != nil {
+			current.head += marking[0]
+			current.marking = marking[1]
+			current.level = marking[2]
+			current.text = rest[len(marking[0]):]
+		}
+	}
+	return m, nil
+}
+
+// separator returns the space between a marking and a sealed value or
+// placeholder. The space after a marking is part of the sealed text, so an
+// opened portion is reassembled byte for byte.
+func (p portion) separator() string {
+	if p.marking != "" {
+		return " "
+	}
+	return ""
+}
+
+// render writes the memo with the portions starting at the keys of replaced
+// swapped for their values.
+func (m *memo) render(replaced map[int]string) []byte {
+	var b strings.Builder
+	next := 0
+	for _, p := range m.portions {
+		r, ok := replaced[p.start]
+		if !ok {
+			continue
+		}
+		for _, l := range m.lines[next:p.start] {
+			b.WriteString(l + "\n")
+		}
+		b.WriteString(r + "\n")
+		next = p.end
+	}
+	b.WriteString(strings.Join(m.lines[next:], "\n"))
+	return []byte(b.String())
+}
+
+// bannerLevel returns the level of a classification banner such as
+// "SECRET//NOFORN//FICTIONAL".
+func bannerLevel(banner string) (string, bool) {
+	base, _, _ := strings.Cut(strings.ToUpper(banner), "//")
+	level, ok := bannerLevels[strings.TrimSpace(base)]
+	return level, ok
+}
@@ -0,0 +1,180 @@
+// Package memotdf encrypts the portion-marked paragraphs of USAF memo
+// markdown, the format memo-mcp renders. The frontmatter stays readable and
+// each classified portion is replaced by an inline nanoTDF under the
+// attributes of its marking, so the memo remains a single markdown file.
+// Decrypt reassembles the memo, showing the portions the caller cannot open
+// as [REDACTED]. Derive reads the attributes a whole memo, or the PDF
+// rendered from it, must be encrypted with from its frontmatter, and
+// CheckMarkings guards any plaintext against being encrypted below the
+// classification its banners and portion markings show.
+package memotdf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultNamespace is the attribute namespace of the demo scenario.
+const DefaultNamespace = "https://demo.usaf.mil"
+
+// DefaultFlightPattern matches REACH mission identifiers such as
+// RCH2532101.
+const DefaultFlightPattern = `RCH\d{7}`
+
+// DefaultFlightAttribute is the attribute of each flight referenced by a
+// memo; "{flight}" is replaced by the identifier.
+const DefaultFlightAttribute = "flight_id/value/{flight}"
+
+// Table maps portion markings, and the classification, flight references
+// and functional tags of memo frontmatter, to attributes.
+//
+// Example (YAML; the equivalent JSON document is accepted as well):
+//
+//	namespace: https://demo.usaf.mil
+//	markings:
+//	  U: []
+//	  S: [classification/value/secret-fictional]
+//	  TS: [classification/value/top-secret-fictional]
+//	unmarked: S
+//	flightAttribute: flight_id/value/{flight}
+//	tags:
+//	  maintenance: [functional/value/maintenance]
+type Table struct {
+	// Namespace is prepended to short attribute references such as
+	// "classification/value/secret-fictional".
+	Namespace string `yaml:"namespace" json:"namespace"`
+	// Markings maps a classification level, the part of a portion marking
+	// before any "//" caveat ("S" in "(S//NF)"), to attributes. Portions of a
+	// level mapped to no attributes stay in the clear.
+	Markings map[string][]string `yaml:"markings" json:"markings"`
+	// Unmarked is the level assumed for portions without a marking. When
+	// empty, an unmarked portion is an error.
+	Unmarked string `yaml:"unmarked" json:"unmarked"`
+	// Classifications maps the level of the frontmatter classification
+	// banner ("S" for "SECRET//FICTIONAL") to attributes. When empty,
+	// Markings is used.
+	Classifications map[string][]string `yaml:"classifications" json:"classifications"`
+	// FlightPattern is a regular expression matching the flight identifiers
+	// referenced anywhere in the frontmatter, ignoring case (default
+	// DefaultFlightPattern).
+	FlightPattern string `yaml:"flightPattern" json:"flightPattern"`
+	// FlightAttribute is the attribute of each flight found, with "{flight}"
+	// replaced by the identifier (default DefaultFlightAttribute). Flights
+	// listed in Flights use those attributes instead.
+	FlightAttribute string              `yaml:"flightAttribute" json:"flightAttribute"`
+	Flights         map[string][]string `yaml:"flights" json:"flights"`
+	// Tags maps the functional tags listed under "tags" in the frontmatter
+	// to attributes.
+	Tags map[string][]string `yaml:"tags" json:"tags"`
+
+	flightPattern *regexp.Regexp
+}
+
+// DefaultTable returns the table for the demo scenario: unclassified
+// portions stay in the clear, Secret and Top Secret portions are encrypted
+// with the matching classification attribute, flights map to flight_id and
+// the "maintenance" tag to the functional attribute.
+func DefaultTable() *Table {
+	t, err := parseTable([]byte("namespace: " + DefaultNamespace + `
+markings:
+  U: []
+  S: [classification/value/secret-fictional]
+  TS: [classification/value/top-secret-fictional]
+tags:
+  maintenance: [functional/value/maintenance]
+`))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// LoadTable reads a YAML or JSON table from path.
+func LoadTable(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read marking table: %w", err)
+	}
+	return parseTable(data)
+}
+
+// parseTable parses a YAML or JSON table, normalizes the levels to upper
+// case and expands short attribute references to full FQNs.
+func parseTable(data []byte) (*Table, error) {
+	var t Table
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse marking table: %w", err)
+	}
+	if len(t.Markings) == 0 && len(t.Classifications) == 0 {
+		return nil, fmt.Errorf("marking table maps no markings or classifications")
+	}
+
+	var err error
+	if t.Markings, err = t.expandMap(t.Markings, strings.ToUpper, "marking"); err != nil {
+		return nil, err
+	}
+	if t.Classifications, err = t.expandMap(t.Classifications, strings.ToUpper, "classification"); err != nil {
+		return nil, err
+	}
+	if len(t.Classifications) == 0 {
+		t.Classifications = t.Markings
+	}
+	if t.Flights, err = t.expandMap(t.Flights, strings.ToUpper, "flight"); err != nil {
+		return nil, err
+	}
+	if t.Tags, err = t.expandMap(t.Tags, strings.ToLower, "tag"); err != nil {
+		return nil, err
+	}
+
+	t.Unmarked = strings.ToUpper(strings.TrimSpace(t.Unmarked))
+	if _, ok := t.Markings[t.Unmarked]; t.Unmarked != "" && !ok {
+		return nil, fmt.Errorf("unmarked level %s is not in the marking table", t.Unmarked)
+	}
+
+	if t.FlightPattern == "" {
+		t.FlightPattern = DefaultFlightPattern
+	}
+	if t.flightPattern, err = regexp.Compile("(?i)" + t.FlightPattern); err != nil {
+		return nil, fmt.Errorf("invalid flight pattern %q: %w", t.FlightPattern, err)
+	}
+	if t.FlightAttribute == "" {
+		t.FlightAttribute = DefaultFlightAttribute
+	}
+	if !strings.Contains(t.FlightAttribute, "{flight}") {
+		return nil, fmt.Errorf("flight attribute %q does not contain {flight}", t.FlightAttribute)
+	}
+	return &t, nil
+}
+
+// expandMap normalizes the keys of m with norm and expands the attributes
+// to full FQNs.
+func (t *Table) expandMap(m map[string][]string, norm func(string) string, kind string) (map[string][]string, error) {
+	out := make(map[string][]string, len(m))
+	for key, attrs := range m {
+		expanded := make([]string, 0, len(attrs))
+		for _, a := range attrs {
+			fqn, err := tdf.ExpandAttribute(t.Namespace, a)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", kind, key, err)
+			}
+			expanded = append(expanded, fqn)
+		}
+		out[norm(strings.TrimSpace(key))] = expanded
+	}
+	return out, nil
+}
+
+// attributesFor returns the attributes for portions of level.
+func (t *Table) attributesFor(level string) ([]string, error) {
+	attrs, ok := t.Markings[level]
+	if !ok {
+		return nil, fmt.Errorf("no attributes for marking (%s); add it to the marking table", level)
+	}
+	return attrs, nil
+}
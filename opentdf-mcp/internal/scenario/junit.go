@@ -0,0 +1,56 @@
+package scenario
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// junitSuites is the JUnit XML report understood by CI systems.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the outcomes as a JUnit XML report with a test case per
+// document and persona, failed where the result was not as expected.
+func WriteJUnit(w io.Writer, outcomes []Outcome) error {
+	suite := junitSuite{Name: "verify-scenario", Tests: len(outcomes)}
+	for _, o := range outcomes {
+		c := junitCase{ClassName: o.Document, Name: o.Persona}
+		if !o.Match() {
+			suite.Failures++
+			c.Failure = &junitFailure{Message: o.Describe(), Text: o.Error}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,207 @@
+// Package scenario checks a directory of TDFs against an expected access
+// matrix by decrypting every file as every persona, so a policy regression
+// is caught before a demo rather than during it.
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/batch"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"github.com/opentdf/platform/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// Expected outcomes of a decrypt attempt.
+const (
+	Allowed = batch.StatusAllowed
+	Denied  = string(tdf.KindDenied)
+)
+
+// Expected is an expected-matrix file: the personas, the TDFs and who may
+// open each.
+type Expected struct {
+	// Directory holds the TDFs; a relative path is resolved against the
+	// expected-matrix file.
+	Directory string     `yaml:"directory" json:"directory"`
+	Personas  []Persona  `yaml:"personas" json:"personas"`
+	Documents []Document `yaml:"documents" json:"documents"`
+}
+
+// Persona is an identity decrypts are attempted as.
+type Persona struct {
+	Name         string `yaml:"name" json:"name"`
+	ClientID     string `yaml:"client_id" json:"client_id"`
+	ClientSecret string `yaml:"client_secret" json:"client_secret"`
+}
+
+// Document is a TDF and the personas expected to open it; every other
+// persona is expected to be denied.
+type Document struct {
+	// File is the path of the TDF relative to Directory.
+	File string `yaml:"file" json:"file"`
+	// Allowed lists personas by client ID or name.
+	Allowed []string `yaml:"allowed" json:"allowed"`
+}
+
+// Load reads a YAML or JSON expected-matrix file.
+func Load(path string) (*Expected, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expected matrix: %w", err)
+	}
+	exp, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !filepath.IsAbs(exp.Directory) {
+		exp.Directory = filepath.Join(filepath.Dir(path), exp.Directory)
+	}
+	return exp, nil
+}
+
+// Parse parses and validates an expected-matrix file.
+func Parse(data []byte) (*Expected, error) {
+	var exp Expected
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &exp); err != nil {
+		return nil, fmt.Errorf("failed to parse expected matrix: %w", err)
+	}
+	if exp.Directory == "" {
+		return nil, fmt.Errorf("expected matrix has no directory")
+	}
+	if len(exp.Personas) == 0 || len(exp.Documents) == 0 {
+		return nil, fmt.Errorf("expected matrix needs personas and documents")
+	}
+	for i, p := range exp.Personas {
+		if p.ClientID == "" || p.ClientSecret == "" {
+			return nil, fmt.Errorf("persona %d needs client_id and client_secret", i+1)
+		}
+		if p.Name == "" {
+			exp.Personas[i].Name = p.ClientID
+		}
+	}
+	for _, d := range exp.Documents {
+		if d.File == "" {
+			return nil, fmt.Errorf("document without a file")
+		}
+		for _, a := range d.Allowed {
+			if exp.persona(a) < 0 {
+				return nil, fmt.Errorf("%s: unknown persona %q", d.File, a)
+			}
+		}
+	}
+	return &exp, nil
+}
+
+// persona returns the index of the persona with client ID or name ref.
+func (e *Expected) persona(ref string) int {
+	return slices.IndexFunc(e.Personas, func(p Persona) bool {
+		return p.ClientID == ref || strings.EqualFold(p.Name, ref)
+	})
+}
+
+// Outcome compares the expected and actual result of decrypting one file as
+// one persona.
+type Outcome struct {
+	Document string `json:"document"`
+	Persona  string `json:"persona"`
+	// Expected is allowed or denied, or empty for a file the matrix does
+	// not list.
+	Expected string `json:"expected"`
+	// Actual is a batch decrypt status, or "missing" for a listed file
+	// that does not exist.
+	Actual string `json:"actual"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StatusMissing is the actual result for a listed document that is not in
+// the directory.
+const StatusMissing = "missing"
+
+// Match reports whether the attempt turned out as expected.
+func (o Outcome) Match() bool {
+	return o.Expected != "" && o.Expected == o.Actual
+}
+
+// Describe explains the outcome, e.g. "expected denied, got allowed".
+func (o Outcome) Describe() string {
+	switch {
+	case o.Expected == "":
+		return fmt.Sprintf("not in the expected matrix, got %s", o.Actual)
+	case o.Match():
+		return o.Actual
+	}
+	return fmt.Sprintf("expected %s, got %s", o.Expected, o.Actual)
+}
+
+// Connect creates an SDK client authenticated as a persona.
+type Connect func(p Persona) (*sdk.SDK, error)
+
+// Verify decrypts every file in the directory as every persona and returns
+// an outcome per document and persona, in persona order. Files the matrix
+// does not list are reported with no expectation.
+func Verify(ctx context.Context, exp *Expected, connect Connect, workers int) ([]Outcome, error) {
+	var outcomes []Outcome
+	for _, p := range exp.Personas {
+		client, err := connect(p)
+		if err != nil {
+			// Credentials that do not work fail every document for this
+			// persona rather than the whole run
+			for _, d := range exp.Documents {
+				outcomes = append(outcomes, Outcome{Document: d.File, Persona: p.Name, Expected: exp.expected(d, p), Actual: string(tdf.KindOther), Error: err.Error()})
+			}
+			continue
+		}
+		results, err := batch.DecryptDir(ctx, client, exp.Directory, batch.DecryptOptions{Workers: workers})
+		client.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		byPath := make(map[string]batch.DecryptResult, len(results))
+		for _, r := range results {
+			byPath[r.Path] = r
+		}
+		for _, d := range exp.Documents {
+			o := Outcome{Document: d.File, Persona: p.Name, Expected: exp.expected(d, p), Actual: StatusMissing}
+			if r, ok := byPath[filepath.ToSlash(d.File)]; ok {
+				o.Actual, o.Error = r.Status, r.Error
+				delete(byPath, r.Path)
+			}
+			outcomes = append(outcomes, o)
+		}
+		for _, r := range results {
+			if _, ok := byPath[r.Path]; ok {
+				outcomes = append(outcomes, Outcome{Document: r.Path, Persona: p.Name, Actual: r.Status, Error: r.Error})
+			}
+		}
+	}
+	return outcomes, nil
+}
+
+// expected returns the expected result of decrypting d as p.
+func (e *Expected) expected(d Document, p Persona) string {
+	for _, a := range d.Allowed {
+		if i := e.persona(a); i >= 0 && e.Personas[i].ClientID == p.ClientID {
+			return Allowed
+		}
+	}
+	return Denied
+}
+
+// Mismatches returns the outcomes that did not turn out as expected.
+func Mismatches(outcomes []Outcome) []Outcome {
+	var mismatches []Outcome
+	for _, o := range outcomes {
+		if !o.Match() {
+			mismatches = append(mismatches, o)
+		}
+	}
+	return mismatches
+}
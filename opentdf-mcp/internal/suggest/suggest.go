@@ -0,0 +1,233 @@
+// Package suggest proposes attribute FQNs for plaintext from signals found
+// in its content, such as flight identifiers, tail numbers, classification
+// banners and maintenance vocabulary. The signals are regular expression
+// rules loaded from a file, with built-in defaults for the demo scenario.
+package suggest
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
+	"gopkg.in/yaml.v3"
+)
+
+// Confidence levels, from strongest to weakest.
+const (
+	High   = "high"
+	Medium = "medium"
+	Low    = "low"
+)
+
+var confidenceRank = map[string]int{High: 3, Medium: 2, Low: 1}
+
+// Platform check results for a suggestion.
+const (
+	// StatusDefined means the attribute value exists on the platform.
+	StatusDefined = "defined"
+	// StatusUndefined means the platform has no such attribute value, so
+	// encrypting with it would fail.
+	StatusUndefined = "undefined"
+	// StatusUnchecked means the platform was not consulted.
+	StatusUnchecked = "unchecked"
+)
+
+// maxEvidence caps the evidence kept per suggestion; Occurrences still
+// counts every match.
+const maxEvidence = 5
+
+//go:embed default_rules.yaml
+var defaultRules []byte
+
+// Rules is a set of signals and the attributes they suggest.
+type Rules struct {
+	// Namespace is prepended to short attribute references such as
+	// "flight_id/value/RCH2532101".
+	Namespace string `yaml:"namespace" json:"namespace"`
+	Rules     []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule suggests attributes wherever Pattern matches.
+type Rule struct {
+	Name    string `yaml:"name" json:"name"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Attribute is suggested for every match; $0, $1 or ${name} are
+	// replaced by the match and its groups.
+	Attribute string `yaml:"attribute" json:"attribute"`
+	// Values maps the text of Group (0, the whole match, by default) to an
+	// attribute, compared ignoring case. Matches not listed are ignored.
+	Values map[string]string `yaml:"values" json:"values"`
+	Group  int               `yaml:"group" json:"group"`
+	// Confidence is high, medium (default) or low.
+	Confidence string `yaml:"confidence" json:"confidence"`
+
+	re *regexp.Regexp
+}
+
+// DefaultRules returns the built-in rules for the demo scenario.
+func DefaultRules() *Rules {
+	r, err := ParseRules(defaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadRules reads YAML or JSON rules from path.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suggestion rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// ParseRules parses YAML or JSON rules, compiles their patterns and
+// expands short attribute references to full FQNs.
+func ParseRules(data []byte) (*Rules, error) {
+	var r Rules
+	// JSON is a subset of YAML, so a single decoder handles both.
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse suggestion rules: %w", err)
+	}
+	if len(r.Rules) == 0 {
+		return nil, fmt.Errorf("suggestion rules define no rules")
+	}
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		var err error
+		if rule.re, err = regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern: %w", rule.Name, err)
+		}
+		if (rule.Attribute == "") == (len(rule.Values) == 0) {
+			return nil, fmt.Errorf("%s: exactly one of attribute and values is required", rule.Name)
+		}
+		if rule.Attribute != "" {
+			if _, err := tdf.ExpandAttribute(r.Namespace, rule.Attribute); err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Name, err)
+			}
+		}
+		if rule.Group < 0 || rule.Group > rule.re.NumSubexp() {
+			return nil, fmt.Errorf("%s: pattern has no group %d", rule.Name, rule.Group)
+		}
+		switch rule.Confidence {
+		case "":
+			rule.Confidence = Medium
+		case High, Medium, Low:
+		default:
+			return nil, fmt.Errorf("%s: unsupported confidence %q (expected high, medium or low)", rule.Name, rule.Confidence)
+		}
+
+		values := make(map[string]string, len(rule.Values))
+		for k, a := range rule.Values {
+			fqn, err := tdf.ExpandAttribute(r.Namespace, a)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Name, err)
+			}
+			values[strings.ToUpper(k)] = fqn
+		}
+		rule.Values = values
+	}
+	return &r, nil
+}
+
+// Evidence is one match supporting a suggestion.
+type Evidence struct {
+	Rule string `json:"rule"`
+	// Line is the line of the match, counting from 1.
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Suggestion is an attribute proposed for the scanned content.
+type Suggestion struct {
+	Attribute  string `json:"attribute"`
+	Confidence string `json:"confidence"`
+	// Status reports whether the platform defines the attribute value.
+	Status      string     `json:"status"`
+	Occurrences int        `json:"occurrences"`
+	Evidence    []Evidence `json:"evidence"`
+}
+
+// Scan applies every rule to text and returns one suggestion per attribute,
+// strongest first. A suggestion carries the highest confidence among the
+// rules that produced it.
+func (r *Rules) Scan(text string) []Suggestion {
+	byAttr := map[string]*Suggestion{}
+	var order []string
+	for _, rule := range r.Rules {
+		for _, loc := range rule.re.FindAllStringSubmatchIndex(text, -1) {
+			attr, ok := rule.attributeFor(text, loc, r.Namespace)
+			if !ok {
+				continue
+			}
+			key := strings.ToLower(attr)
+			s, ok := byAttr[key]
+			if !ok {
+				s = &Suggestion{Attribute: attr, Confidence: rule.Confidence, Status: StatusUnchecked}
+				byAttr[key] = s
+				order = append(order, key)
+			}
+			if confidenceRank[rule.Confidence] > confidenceRank[s.Confidence] {
+				s.Confidence = rule.Confidence
+			}
+			s.Occurrences++
+			if len(s.Evidence) < maxEvidence {
+				s.Evidence = append(s.Evidence, Evidence{
+					Rule: rule.Name,
+					Line: strings.Count(text[:loc[0]], "\n") + 1,
+					Text: text[loc[0]:loc[1]],
+				})
+			}
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(order))
+	for _, key := range order {
+		suggestions = append(suggestions, *byAttr[key])
+	}
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		if d := confidenceRank[b.Confidence] - confidenceRank[a.Confidence]; d != 0 {
+			return d
+		}
+		return b.Occurrences - a.Occurrences
+	})
+	return suggestions
+}
+
+// attributeFor returns the attribute a match at loc suggests.
+func (rule *Rule) attributeFor(text string, loc []int, namespace string) (string, bool) {
+	if len(rule.Values) > 0 {
+		start, end := loc[2*rule.Group], loc[2*rule.Group+1]
+		if start < 0 {
+			return "", false
+		}
+		attr, ok := rule.Values[strings.ToUpper(text[start:end])]
+		return attr, ok
+	}
+	expanded := rule.re.ExpandString(nil, rule.Attribute, text, loc)
+	fqn, err := tdf.ExpandAttribute(namespace, string(expanded))
+	if err != nil {
+		return "", false
+	}
+	return fqn, true
+}
+
+// Check sets the Status of each suggestion from the attribute value FQNs
+// defined on the platform, compared ignoring case.
+func Check(suggestions []Suggestion, defined []string) {
+	for i := range suggestions {
+		suggestions[i].Status = StatusUndefined
+		if slices.ContainsFunc(defined, func(d string) bool { return strings.EqualFold(d, suggestions[i].Attribute) }) {
+			suggestions[i].Status = StatusDefined
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package tdf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/platform/protocol/go/common"
+	"github.com/opentdf/platform/protocol/go/policy/attributes"
+	"github.com/opentdf/platform/protocol/go/policy/namespaces"
+	"github.com/opentdf/platform/sdk"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// maxSuggestions caps the "did you mean" candidates offered for an unknown
+// attribute value.
+const maxSuggestions = 3
+
+// DefinedValue is an attribute value defined on the platform.
+type DefinedValue struct {
+	FQN    string
+	Active bool
+}
+
+// ListAttributeValues returns the FQN of every active attribute value
+// defined on the platform, across all namespaces.
+func ListAttributeValues(ctx context.Context, client *sdk.SDK) ([]string, error) {
+	values, err := listValues(ctx, client, common.ActiveStateEnum_ACTIVE_STATE_ENUM_ACTIVE)
+	if err != nil {
+		return nil, err
+	}
+	fqns := make([]string, 0, len(values))
+	for _, v := range values {
+		if v.Active {
+			fqns = append(fqns, v.FQN)
+		}
+	}
+	return fqns, nil
+}
+
+// listValues returns the attribute values in state across all namespaces.
+func listValues(ctx context.Context, client *sdk.SDK, state common.ActiveStateEnum) ([]DefinedValue, error) {
+	listResp, err := client.Namespaces.ListNamespaces(ctx, &namespaces.ListNamespacesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var values []DefinedValue
+	for _, n := range listResp.GetNamespaces() {
+		u, err := url.Parse(n.GetFqn())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse namespace URL: %w", err)
+		}
+		lsr, err := client.Attributes.ListAttributes(ctx, &attributes.ListAttributesRequest{
+			State:     state,
+			Namespace: u.Host,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list attributes: %w", err)
+		}
+		for _, a := range lsr.GetAttributes() {
+			for _, v := range a.GetValues() {
+				values = append(values, DefinedValue{FQN: v.GetFqn(), Active: isActive(a) && isActive(v)})
+			}
+		}
+	}
+	return values, nil
+}
+
+// isActive reports whether an attribute or value is active; the platform
+// leaves the flag unset on objects that were never deactivated.
+func isActive(o interface{ GetActive() *wrapperspb.BoolValue }) bool {
+	return o.GetActive() == nil || o.GetActive().GetValue()
+}
+
+// NormalizeAttributes trims and lower-cases attribute value FQNs, as the
+// platform stores them, and rejects any that do not parse as
+// https://<namespace>/attr/<name>/value/<value>.
+func NormalizeAttributes(fqns []string) ([]string, error) {
+	normalized := make([]string, 0, len(fqns))
+	for _, a := range fqns {
+		fqn := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(a), "/"))
+		if _, err := sdk.NewAttributeValueFQN(fqn); err != nil {
+			return nil, fmt.Errorf("invalid attribute FQN %q: expected https://<namespace>/attr/<name>/value/<value>", a)
+		}
+		if !slices.Contains(normalized, fqn) {
+			normalized = append(normalized, fqn)
+		}
+	}
+	return normalized, nil
+}
+
+// VerifyAttributes normalizes fqns and confirms that each names an active
+// attribute value on the platform, so a typo cannot produce a file nobody
+// can decrypt.
+func VerifyAttributes(ctx context.Context, client *sdk.SDK, fqns []string) ([]string, error) {
+	normalized, err := NormalizeAttributes(fqns)
+	if err != nil || len(normalized) == 0 {
+		return normalized, err
+	}
+	defined, err := listValues(ctx, client, common.ActiveStateEnum_ACTIVE_STATE_ENUM_ANY)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attributes against the platform: %w", err)
+	}
+	if err := CheckAttributes(normalized, defined); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// CheckAttributes reports the normalized fqns that are not active values
+// among defined, suggesting the closest active values for unknown ones.
+func CheckAttributes(fqns []string, defined []DefinedValue) error {
+	var problems []string
+	for _, fqn := range fqns {
+		i := slices.IndexFunc(defined, func(d DefinedValue) bool { return strings.EqualFold(d.FQN, fqn) })
+		switch {
+		case i >= 0 && defined[i].Active:
+			continue
+		case i >= 0:
+			problems = append(problems, fmt.Sprintf("attribute value %s is inactive", fqn))
+		default:
+			msg := fmt.Sprintf("attribute value %s does not exist", fqn)
+			if similar := suggestValues(fqn, defined); len(similar) > 0 {
+				msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(similar, " or "))
+			}
+			problems = append(problems, msg)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// suggestValues returns the active values closest to fqn by edit distance,
+// nearest first.
+func suggestValues(fqn string, defined []DefinedValue) []string {
+	type candidate struct {
+		fqn      string
+		distance int
+	}
+	// Allow roughly one edit per five characters of the attribute name and
+	// value, so a misspelt name still finds its values
+	limit := max(3, len(fqn[strings.Index(fqn, "/attr/"):])/5)
+
+	var candidates []candidate
+	for _, d := range defined {
+		if !d.Active {
+			continue
+		}
+		other := strings.ToLower(d.FQN)
+		dist := editDistance(fqn, other)
+		// A value of the same attribute that extends or shortens the one
+		// asked for ("secret" for "secret-fictional") is also a likely intent
+		if dist <= limit || sameAttributeAffix(fqn, other) {
+			candidates = append(candidates, candidate{d.FQN, dist})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return a.distance - b.distance })
+
+	var similar []string
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		similar = append(similar, c.fqn)
+	}
+	return similar
+}
+
+// sameAttributeAffix reports whether two value FQNs share an attribute and
+// one value contains the other.
+func sameAttributeAffix(a, b string) bool {
+	ai, bi := strings.LastIndex(a, "/value/"), strings.LastIndex(b, "/value/")
+	if ai < 0 || bi < 0 || a[:ai] != b[:bi] {
+		return false
+	}
+	av, bv := a[ai+len("/value/"):], b[bi+len("/value/"):]
+	return av != "" && bv != "" && (strings.Contains(av, bv) || strings.Contains(bv, av))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,56 @@
+package tdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	nanoMagic = []byte("L1L")
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// ErrNotBase64TDF is returned by DecodeBase64TDF when the input does not
+// decode to a nanoTDF or ZTDF container.
+var ErrNotBase64TDF = errors.New("not base64 encoded TDF data")
+
+// base64Encodings lists the alphabets accepted for inline TDF payloads, in
+// the order they are tried.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// DecodeBase64TDF decodes an inline TDF payload in the standard or URL-safe
+// base64 alphabet, padded or not. Whitespace and an optional data: URL
+// prefix are ignored. The decoded bytes must start with the nanoTDF or ZIP
+// magic so that arbitrary strings (such as mistyped file paths) are not
+// mistaken for ciphertext.
+func DecodeBase64TDF(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "data:") {
+		if i := strings.Index(s, ";base64,"); i >= 0 {
+			s = s[i+len(";base64,"):]
+		}
+	}
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return nil, ErrNotBase64TDF
+	}
+
+	for _, enc := range base64Encodings {
+		data, err := enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if bytes.HasPrefix(data, nanoMagic) || bytes.HasPrefix(data, zipMagic) {
+			return data, nil
+		}
+		return nil, ErrNotBase64TDF
+	}
+	return nil, ErrNotBase64TDF
+}
@@ -0,0 +1,177 @@
+package tdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// ConvertOptions configures Convert.
+type ConvertOptions struct {
+	Target Format
+	// Attributes is the complete target policy. When empty the source
+	// policy is copied, which requires it to be readable. A target that
+	// drops any readable source attribute is refused.
+	Attributes []string
+	// AllowUnverified accepts Attributes for a source whose policy is
+	// encrypted, so it cannot be checked that the target is not weaker.
+	// Without it such a conversion is refused.
+	AllowUnverified bool
+	// AllowPlaintextPolicy accepts writing the attributes of a nanoTDF
+	// whose policy is encrypted into a ZTDF manifest, where anyone holding
+	// the file can read them. Without it such a conversion is refused.
+	AllowPlaintextPolicy bool
+	// KasURL is used when the source does not name a KAS.
+	KasURL string
+	// PolicyMode and Binding apply to a nanoTDF target.
+	PolicyMode PolicyMode
+	Binding    Binding
+}
+
+// ErrUnverifiedPolicy is returned for target attributes given for a source
+// whose policy is encrypted, unless AllowUnverified is set.
+var ErrUnverifiedPolicy = errors.New("refusing to convert: the source nanoTDF policy is encrypted, so the target policy cannot be checked against it and could be weaker")
+
+// ErrExposedPolicy is returned for a ZTDF target of a nanoTDF whose policy is
+// encrypted, unless AllowPlaintextPolicy is set: the ZTDF manifest stores the
+// policy unencrypted, so the conversion would disclose its attribute values.
+var ErrExposedPolicy = errors.New("refusing to convert: the source nanoTDF policy is encrypted, but the ZTDF manifest stores the policy unencrypted, so its attribute values would be readable without a key")
+
+// ConvertResult reports what Convert produced.
+type ConvertResult struct {
+	Source Format `json:"source"`
+	Target Format `json:"target"`
+	// SourceAttributes is nil when the source policy could not be read.
+	SourceAttributes []string `json:"sourceAttributes"`
+	TargetAttributes []string `json:"targetAttributes"`
+	// PolicyVerified is false when the source policy was encrypted, so the
+	// target policy could not be checked against it.
+	PolicyVerified bool     `json:"policyVerified"`
+	Warnings       []string `json:"warnings,omitempty"`
+	PlaintextSize  int64    `json:"plaintextSize"`
+	EncryptedSize  int64    `json:"encryptedSize"`
+}
+
+// Convert re-encapsulates the TDF in r in the other container format,
+// writing the result to w. The target carries the same attribute policy
+// and KAS as the source. ZTDF sources are streamed from the SDK reader;
+// nanoTDF sources are at most 16 MiB and are decrypted into memory.
+func Convert(ctx context.Context, client *sdk.SDK, w io.Writer, r io.ReadSeeker, opts ConvertOptions) (ConvertResult, error) {
+	info, err := Inspect(r)
+	if err != nil {
+		return ConvertResult{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return ConvertResult{}, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+
+	source := Format(info.Format)
+	if source == opts.Target {
+		return ConvertResult{}, fmt.Errorf("input is already %s; use relabel to change its policy", source)
+	}
+	srcOpts, srcAttrs, err := sourceOptions(info, opts.KasURL)
+	if err != nil {
+		return ConvertResult{}, err
+	}
+
+	result := ConvertResult{Source: source, Target: opts.Target, SourceAttributes: srcAttrs}
+	if result.TargetAttributes, result.PolicyVerified, err = targetPolicy(srcAttrs, opts.Attributes, opts.AllowUnverified); err != nil {
+		return ConvertResult{}, err
+	}
+	exposed := opts.Target == FormatZTDF && srcOpts.PolicyMode != PolicyPlaintext && len(result.TargetAttributes) > 0
+	if exposed && !opts.AllowPlaintextPolicy {
+		return ConvertResult{}, ErrExposedPolicy
+	}
+	if !result.PolicyVerified {
+		result.Warnings = append(result.Warnings, "the source policy is encrypted, so the target policy was not checked against it")
+	}
+
+	encOpts := EncryptOptions{
+		Format:     opts.Target,
+		Attributes: result.TargetAttributes,
+		KasURL:     srcOpts.KasURL,
+	}
+	if opts.Target == FormatNano {
+		encOpts.PolicyMode = opts.PolicyMode
+		encOpts.Binding = opts.Binding
+		if srcOpts.MimeType != "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("nanoTDF has no MIME type; %s is not kept", srcOpts.MimeType))
+		}
+		if len(info.ZTDF.Assertions) > 0 {
+			result.Warnings = append(result.Warnings, "nanoTDF has no assertions; the source assertions are dropped")
+		}
+	} else if exposed {
+		result.Warnings = append(result.Warnings, "the ZTDF manifest stores the policy unencrypted, so its attribute values are readable without a key")
+	}
+
+	var plaintext io.ReadSeeker
+	if source == FormatZTDF {
+		tdfReader, err := client.LoadTDF(r)
+		if err != nil {
+			return ConvertResult{}, fmt.Errorf("failed to load TDF: %w", err)
+		}
+		metadata, err := tdfReader.UnencryptedMetadata()
+		if err != nil {
+			return ConvertResult{}, fmt.Errorf("failed to read TDF metadata: %w", err)
+		}
+		if len(metadata) > 0 {
+			result.Warnings = append(result.Warnings, "nanoTDF has no metadata; the source metadata is dropped")
+		}
+		plaintext = tdfReader
+	} else {
+		// Decrypt into memory only; the buffer is wiped once re-encrypted
+		var buf bytes.Buffer
+		defer func() { clear(buf.Bytes()) }()
+		if err := Decrypt(ctx, client, &buf, r, source); err != nil {
+			return ConvertResult{}, err
+		}
+		plaintext = bytes.NewReader(buf.Bytes())
+		if encOpts.MimeType, err = DetectMimeType("", plaintext); err != nil {
+			return ConvertResult{}, err
+		}
+	}
+
+	encrypted, err := Encrypt(client, w, plaintext, encOpts)
+	if err != nil {
+		return ConvertResult{}, err
+	}
+	result.PlaintextSize = encrypted.PlaintextSize
+	result.EncryptedSize = encrypted.EncryptedSize
+	return result, nil
+}
+
+// targetPolicy picks the target attributes and reports whether they could
+// be verified against the source. srcAttrs is nil for an unreadable policy,
+// in which case requested is only accepted with allowUnverified.
+func targetPolicy(srcAttrs, requested []string, allowUnverified bool) ([]string, bool, error) {
+	if len(requested) == 0 {
+		if srcAttrs == nil {
+			return nil, false, fmt.Errorf("the source nanoTDF policy is encrypted and cannot be copied; pass the attributes it was encrypted with")
+		}
+		return srcAttrs, true, nil
+	}
+
+	target := normalizeAttributes(requested)
+	if srcAttrs == nil {
+		if !allowUnverified {
+			return nil, false, ErrUnverifiedPolicy
+		}
+		return target, false, nil
+	}
+	var missing []string
+	for _, a := range srcAttrs {
+		if !slices.ContainsFunc(target, func(b string) bool { return strings.EqualFold(a, b) }) {
+			missing = append(missing, a)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, false, fmt.Errorf("refusing to weaken the policy: the target would drop %s", strings.Join(missing, ", "))
+	}
+	return target, true, nil
+}
@@ -0,0 +1,30 @@
+package tdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// Decrypt writes the plaintext of the TDF in r to w. ZTDF payloads are
+// streamed segment by segment.
+func Decrypt(ctx context.Context, client *sdk.SDK, w io.Writer, r io.ReadSeeker, format Format) error {
+	if format == FormatNano {
+		if _, err := client.ReadNanoTDFContext(ctx, w, r); err != nil {
+			return fmt.Errorf("failed to decrypt nanoTDF: %w", err)
+		}
+		return nil
+	}
+
+	tdfReader, err := client.LoadTDF(r)
+	if err != nil {
+		return fmt.Errorf("failed to load TDF: %w", err)
+	}
+	if _, err := io.Copy(w, tdfReader); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decrypt TDF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package tdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// ErrUnsupportedFormat is matched by every *UnsupportedFormatError.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// UnsupportedFormatError reports input that is not a TDF this tool can
+// decrypt, along with what it appears to be instead.
+type UnsupportedFormatError struct {
+	// Detected is a short description such as "plaintext" or
+	// "base64-encoded nanoTDF".
+	Detected string
+	// Hint suggests how to get a usable TDF, when there is an obvious fix.
+	Hint string
+	Err  error
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	msg := "unsupported format: input is " + e.Detected
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Hint != "" {
+		msg += " (" + e.Hint + ")"
+	}
+	return msg
+}
+
+func (e *UnsupportedFormatError) Is(target error) bool {
+	return target == ErrUnsupportedFormat
+}
+
+func (e *UnsupportedFormatError) Unwrap() error {
+	return e.Err
+}
+
+// DetectedPlaintext is the UnsupportedFormatError.Detected value for
+// readable text. Partially encrypted documents, whose sealed values are
+// inline text, are reported this way too.
+const DetectedPlaintext = "plaintext"
+
+// sniffSize is how much of the input is examined to recognise non-TDF data.
+const sniffSize = 512
+
+// nanoMagicPrefix is the nanoTDF magic without the version byte. "L1L" is
+// the only version the SDK reads.
+var nanoMagicPrefix = []byte("L1")
+
+// DetectFormat identifies the TDF container in r and rewinds r to the
+// beginning. Anything other than a readable nanoTDF header or a ZIP archive
+// with a TDF manifest is rejected with an *UnsupportedFormatError that says
+// what the input looks like instead: an unsupported nanoTDF version, a
+// truncated file, base64 text, an HTML page, plaintext, or unknown binary.
+func DetectFormat(r io.ReadSeeker) (Format, error) {
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+
+	format, err := detect(r, head)
+	if _, seekErr := r.Seek(0, io.SeekStart); err == nil && seekErr != nil {
+		err = fmt.Errorf("failed to seek to beginning: %w", seekErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+func detect(r io.ReadSeeker, head []byte) (Format, error) {
+	switch {
+	case len(head) == 0:
+		return "", &UnsupportedFormatError{Detected: "empty"}
+
+	case bytes.HasPrefix(head, nanoMagic):
+		if _, _, err := sdk.NewNanoTDFHeaderFromReader(r); err != nil {
+			return "", &UnsupportedFormatError{Detected: "a truncated or corrupt nanoTDF", Err: err}
+		}
+		return FormatNano, nil
+
+	case bytes.HasPrefix(head, nanoMagicPrefix) && len(head) > len(nanoMagic) && isNanoVersion(head[2]) && head[3] < 0x20:
+		return "", &UnsupportedFormatError{
+			Detected: fmt.Sprintf("a nanoTDF with unsupported version %q", head[:3]),
+			Hint:     "only L1L nanoTDFs can be read; re-encrypt with a current client",
+		}
+
+	case bytes.HasPrefix(head, zipMagic) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		if err := checkZTDF(r); err != nil {
+			return "", err
+		}
+		return FormatZTDF, nil
+	}
+
+	text := bytes.TrimSpace(head)
+	if what := base64Wrapped(text); what != "" {
+		return "", &UnsupportedFormatError{
+			Detected: "a base64-encoded " + what,
+			Hint:     "decode it first, e.g. base64 -d, or pass the text inline to the decrypt tool",
+		}
+	}
+	if looksLikeHTML(text) {
+		if htmlWrapsTDF(r) {
+			return "", &UnsupportedFormatError{
+				Detected: "an HTML-wrapped TDF",
+				Hint:     "extract the base64 payload from the HTML and decode it first",
+			}
+		}
+		return "", &UnsupportedFormatError{
+			Detected: "an HTML document",
+			Hint:     "this is often an error or login page saved in place of the TDF; download the raw file",
+		}
+	}
+	if isText(head) {
+		return "", &UnsupportedFormatError{Detected: DetectedPlaintext, Hint: "the file is not encrypted"}
+	}
+	return "", &UnsupportedFormatError{Detected: "unrecognized binary data"}
+}
+
+// isNanoVersion reports whether b is plausibly a nanoTDF version byte
+// (an upper case letter), so "L1K" is reported as an old nanoTDF rather
+// than as unknown data. Callers also require the following resource
+// locator protocol byte to be binary so text such as "L1A" is not matched.
+func isNanoVersion(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+// checkZTDF verifies that a ZIP archive is complete and holds a TDF
+// manifest.
+func checkZTDF(r io.ReadSeeker) error {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to determine input size: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+	ra, err := readerAt(r)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return &UnsupportedFormatError{Detected: "a truncated or corrupt ZIP archive", Err: err}
+	}
+	for _, f := range zr.File {
+		if f.Name == ztdfManifestName {
+			return nil
+		}
+	}
+	return &UnsupportedFormatError{
+		Detected: "a ZIP archive without a TDF manifest",
+		Hint:     "ZTDF archives contain " + ztdfManifestName,
+	}
+}
+
+// readerAt returns r as an io.ReaderAt, reading it into memory when it does
+// not support random access itself.
+func readerAt(r io.ReadSeeker) (io.ReaderAt, error) {
+	if ra, ok := r.(io.ReaderAt); ok {
+		return ra, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TDF: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+// base64Wrapped reports which container the start of a base64 text encodes,
+// or "" if it is not base64 TDF data. Only the first few bytes are decoded
+// since head may end mid-quantum.
+func base64Wrapped(text []byte) string {
+	s := string(text)
+	if i := strings.Index(s, ";base64,"); strings.HasPrefix(s, "data:") && i >= 0 {
+		s = s[i+len(";base64,"):]
+	}
+	s = strings.Join(strings.Fields(s), "")
+	if len(s) < 8 {
+		return ""
+	}
+	for _, enc := range base64Encodings {
+		data, err := enc.Strict().DecodeString(s[:8])
+		if err != nil {
+			continue
+		}
+		switch {
+		case bytes.HasPrefix(data, nanoMagic):
+			return "nanoTDF"
+		case bytes.HasPrefix(data, zipMagic):
+			return "ZTDF"
+		}
+	}
+	return ""
+}
+
+func looksLikeHTML(text []byte) bool {
+	lower := strings.ToLower(string(text))
+	return strings.HasPrefix(lower, "<!doctype html") || strings.HasPrefix(lower, "<html") ||
+		(strings.HasPrefix(lower, "<") && strings.Contains(lower, "<body"))
+}
+
+// htmlScanLimit bounds how much of an HTML document is searched for an
+// embedded base64 TDF.
+const htmlScanLimit = 1 << 20
+
+// htmlWrapsTDF reports whether an HTML document embeds base64 TDF data, as
+// the legacy TDF HTML wrapper does.
+func htmlWrapsTDF(r io.Reader) bool {
+	data, err := io.ReadAll(io.LimitReader(r, htmlScanLimit))
+	if err != nil {
+		return false
+	}
+	// "UEsDB" and "TDFM" are the base64 forms of the ZIP and nanoTDF magic
+	return bytes.Contains(data, []byte("UEsDB")) || bytes.Contains(data, []byte("TDFM"))
+}
+
+// isText reports whether head looks like human readable text: valid UTF-8
+// (allowing a rune cut off at the end) with no NUL or other control bytes
+// besides whitespace.
+func isText(head []byte) bool {
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		if r == utf8.RuneError && size <= 1 {
+			// A multi-byte rune truncated by the sniff window is fine
+			return len(head) < utf8.UTFMax && !utf8.FullRune(head)
+		}
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' {
+			return false
+		}
+		head = head[size:]
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+// Package tdf holds the OpenTDF encrypt/decrypt plumbing shared by the
+// opentdf-cli and opentdf-mcp-server binaries.
+package tdf
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// Format identifies the TDF container produced by Encrypt.
+type Format string
+
+const (
+	// FormatNano is the compact binary nanoTDF container (.ntdf).
+	FormatNano Format = "nano"
+	// FormatZTDF is the standard zip TDF container (.tdf) with a full manifest.
+	FormatZTDF Format = "ztdf"
+)
+
+// ParseFormat converts a user supplied format name into a Format.
+// An empty string selects nanoTDF to preserve the historical default.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "nano", "nanotdf", "ntdf":
+		return FormatNano, nil
+	case "ztdf", "tdf", "tdf3", "zip":
+		return FormatZTDF, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (expected \"nano\" or \"ztdf\")", s)
+	}
+}
+
+// Extension returns the conventional file extension for the format.
+func (f Format) Extension() string {
+	if f == FormatZTDF {
+		return ".tdf"
+	}
+	return ".ntdf"
+}
+
+// String returns a human readable name for the format.
+func (f Format) String() string {
+	if f == FormatZTDF {
+		return "ZTDF"
+	}
+	return "nanoTDF"
+}
+
+// MediaType returns the MIME type of the encrypted container itself.
+func (f Format) MediaType() string {
+	if f == FormatZTDF {
+		return "application/zip"
+	}
+	return "application/octet-stream"
+}
+
+// MaxNanoPayloadSize is the largest plaintext a single nanoTDF can carry
+// (16 MiB less the IV and maximum auth tag). Larger inputs need ZTDF.
+const MaxNanoPayloadSize = 16*1024*1024 - 3 - 32
+
+// EncryptOptions configures a single Encrypt call.
+type EncryptOptions struct {
+	Format     Format
+	Attributes []string
+	// KasURL is the full KAS endpoint, e.g. http://localhost:8080/kas.
+	KasURL string
+	// MimeType is recorded in the ZTDF manifest. It is ignored for nanoTDF.
+	MimeType string
+	// Metadata is stored encrypted in the ZTDF key access object and is
+	// ignored for nanoTDF.
+	Metadata string
+	// PolicyMode and Binding apply to nanoTDF only; the zero values select
+	// an encrypted, ECDSA-bound policy.
+	PolicyMode PolicyMode
+	Binding    Binding
+}
+
+// KasURL derives the KAS endpoint from the platform endpoint, adding an
+// http:// scheme when none is present.
+func KasURL(platformEndpoint string) string {
+	base := platformEndpoint
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	return strings.TrimSuffix(base, "/") + "/kas"
+}
+
+// DetectMimeType guesses the MIME type of the plaintext for the ZTDF
+// manifest. The file extension of name wins when it is known; otherwise the
+// first 512 bytes of r are sniffed and r is rewound to the start.
+func DetectMimeType(name string, r io.ReadSeeker) (string, error) {
+	if name != "" {
+		if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+			return t, nil
+		}
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read plaintext: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// EncryptResult reports how much data an Encrypt call processed.
+type EncryptResult struct {
+	PlaintextSize int64
+	EncryptedSize int64
+}
+
+// Encrypt streams plaintext from r into a TDF in the requested format written
+// to w. r is read from its start; ZTDF reads it segment by segment so the
+// plaintext is never held in memory as a whole.
+func Encrypt(client *sdk.SDK, w io.Writer, r io.ReadSeeker, opts EncryptOptions) (EncryptResult, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return EncryptResult{}, fmt.Errorf("failed to determine input size: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return EncryptResult{}, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+
+	n, err := encrypt(client, w, r, size, opts)
+	if err != nil {
+		return EncryptResult{}, err
+	}
+	return EncryptResult{PlaintextSize: size, EncryptedSize: n}, nil
+}
+
+func encrypt(client *sdk.SDK, w io.Writer, r io.ReadSeeker, size int64, opts EncryptOptions) (int64, error) {
+	switch opts.Format {
+	case FormatZTDF:
+		if opts.PolicyMode != "" || opts.Binding != "" {
+			return 0, fmt.Errorf("policy mode and binding only apply to nanoTDF; ZTDF always stores its policy in the manifest")
+		}
+		tdfOpts := []sdk.TDFOption{
+			sdk.WithKasInformation(sdk.KASInfo{URL: opts.KasURL}),
+		}
+		if len(opts.Attributes) > 0 {
+			tdfOpts = append(tdfOpts, sdk.WithDataAttributes(opts.Attributes...))
+		}
+		if opts.MimeType != "" {
+			tdfOpts = append(tdfOpts, sdk.WithMimeType(opts.MimeType))
+		}
+		if opts.Metadata != "" {
+			tdfOpts = append(tdfOpts, sdk.WithMetaData(opts.Metadata))
+		}
+
+		obj, err := client.CreateTDF(w, r, tdfOpts...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create ZTDF: %w", err)
+		}
+		return obj.Size(), nil
+
+	case FormatNano, "":
+		if size > MaxNanoPayloadSize {
+			return 0, fmt.Errorf("input is %s but nanoTDF payloads are limited to %s; use the ztdf format", HumanSize(size), HumanSize(MaxNanoPayloadSize))
+		}
+
+		nanoConfig, err := newNanoConfig(client, opts.Attributes, opts.KasURL, opts.PolicyMode, opts.Binding)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := client.CreateNanoTDF(w, r, *nanoConfig)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create nanoTDF: %w", err)
+		}
+		return int64(n), nil
+
+	default:
+		return 0, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+}
+
+// newNanoConfig builds a nanoTDF config for the given policy and KAS.
+func newNanoConfig(client *sdk.SDK, attrs []string, kasURL string, mode PolicyMode, binding Binding) (*sdk.NanoTDFConfig, error) {
+	nanoConfig, err := client.NewNanoTDFConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nanoTDF config: %w", err)
+	}
+
+	// Attributes define the access policy for the encrypted data
+	if len(attrs) > 0 {
+		if err := nanoConfig.SetAttributes(attrs); err != nil {
+			return nil, fmt.Errorf("failed to set attributes: %w", err)
+		}
+	}
+
+	// Choose how the policy is stored and cryptographically bound
+	if err := applyNanoPolicy(nanoConfig, mode, binding); err != nil {
+		return nil, err
+	}
+
+	if err := nanoConfig.SetKasURL(kasURL); err != nil {
+		return nil, fmt.Errorf("failed to set KAS URL: %w", err)
+	}
+	return nanoConfig, nil
+}
@@ -0,0 +1,105 @@
+package tdf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/opentdf/platform/sdk"
+)
+
+// ErrorKind is a coarse classification of a decrypt failure.
+type ErrorKind string
+
+const (
+	// KindDenied means KAS refused to rewrap the key for this entity.
+	KindDenied ErrorKind = "denied"
+	// KindNetwork means the platform or KAS could not be reached.
+	KindNetwork ErrorKind = "network_error"
+	// KindAuth means the caller could not authenticate to the platform.
+	KindAuth ErrorKind = "auth_error"
+	// KindMalformed means the input is not a readable TDF.
+	KindMalformed ErrorKind = "malformed"
+	// KindIntegrity means the TDF failed a signature, hash or policy binding
+	// check, i.e. it was corrupted or tampered with.
+	KindIntegrity ErrorKind = "integrity_error"
+	// KindOther covers every other failure.
+	KindOther ErrorKind = "error"
+)
+
+// Description explains the kind for a person, e.g. "access denied".
+func (k ErrorKind) Description() string {
+	switch k {
+	case KindDenied:
+		return "access denied"
+	case KindNetwork:
+		return "platform or KAS unreachable"
+	case KindAuth:
+		return "authentication failed"
+	case KindMalformed:
+		return "malformed file"
+	case KindIntegrity:
+		return "integrity check failed"
+	}
+	return "decrypt failed"
+}
+
+// ClassifyError maps an error from Decrypt onto an ErrorKind. The SDK
+// flattens KAS errors into strings, so the classification falls back to
+// matching well-known fragments of the message.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, ErrUnsupportedFormat) {
+		return KindMalformed
+	}
+	// The SDK marks every failed integrity check, and KAS rejecting a
+	// policy binding, as tampering
+	if errors.Is(err, sdk.ErrTampered) {
+		return KindIntegrity
+	}
+	switch connect.CodeOf(err) {
+	case connect.CodePermissionDenied:
+		return KindDenied
+	case connect.CodeUnauthenticated:
+		return KindAuth
+	case connect.CodeUnavailable, connect.CodeDeadlineExceeded:
+		return KindNetwork
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return KindNetwork
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case containsAny(msg, "permission_denied", "permission denied", "forbidden", "rewrap request 403"):
+		return KindDenied
+	case containsAny(msg, "connection refused", "no such host", "dial tcp", "i/o timeout",
+		"unavailable", "connection reset", "deadline exceeded"):
+		return KindNetwork
+	case containsAny(msg, "unauthenticated", "unauthorized", "invalid_client", "invalid_grant",
+		"error getting access token", "cannot fetch token", "token expired"):
+		return KindAuth
+	case containsAny(msg, "tamper detected", "integrity check", "message authentication failed",
+		"policy binding", "signature"):
+		return KindIntegrity
+	case containsAny(msg, "magic number", "not a valid nano tdf", "zip: not a valid zip file",
+		"failed to load tdf", "io.reader.read failed", "unsupported policy mode", "manifest"):
+		return KindMalformed
+	}
+	return KindOther
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
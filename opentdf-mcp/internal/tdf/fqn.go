@@ -0,0 +1,21 @@
+package tdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpandAttribute turns a short attribute reference such as
+// "flight_id/value/RCH2532101" into a full FQN under namespace. Full FQNs
+// are returned unchanged.
+func ExpandAttribute(namespace, a string) (string, error) {
+	a = strings.TrimSpace(a)
+	if strings.HasPrefix(a, "http://") || strings.HasPrefix(a, "https://") {
+		return a, nil
+	}
+	if namespace == "" {
+		return "", fmt.Errorf("attribute %q is not a full FQN and no namespace is set", a)
+	}
+	a = strings.TrimPrefix(strings.TrimPrefix(a, "/"), "attr/")
+	return strings.TrimSuffix(namespace, "/") + "/attr/" + a, nil
+}
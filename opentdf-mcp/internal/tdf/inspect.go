@@ -0,0 +1,253 @@
+package tdf
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// ztdfManifestName is the name of the manifest entry inside a ZTDF archive.
+const ztdfManifestName = "0.manifest.json"
+
+// Inspection describes a TDF container without decrypting it. Exactly one
+// of Nano and ZTDF is set, matching Format.
+type Inspection struct {
+	Format string    `json:"format"`
+	Size   int64     `json:"size"`
+	Nano   *NanoInfo `json:"nano,omitempty"`
+	ZTDF   *ZTDFInfo `json:"ztdf,omitempty"`
+}
+
+// NanoInfo holds the fields of a nanoTDF header.
+type NanoInfo struct {
+	KasURL string `json:"kasUrl"`
+	// KasKeyID is the KAS key identifier, when the locator carries one.
+	KasKeyID      string `json:"kasKeyId,omitempty"`
+	ECCMode       string `json:"eccMode"`
+	Cipher        string `json:"cipher"`
+	PolicyType    string `json:"policyType"`
+	PolicyBinding string `json:"policyBinding"`
+	// PolicyBindingValid reports whether the binding matches the policy
+	// body, i.e. whether the header has been tampered with.
+	PolicyBindingValid bool `json:"policyBindingValid"`
+	// Attributes are only readable when the policy is stored in plaintext.
+	Attributes []string `json:"attributes,omitempty"`
+	HeaderSize uint32   `json:"headerSize"`
+}
+
+// ZTDFInfo holds the interesting parts of a ZTDF manifest.json.
+type ZTDFInfo struct {
+	SchemaVersion string             `json:"schemaVersion,omitempty"`
+	MimeType      string             `json:"mimeType,omitempty"`
+	KeyAccess     []sdk.KeyAccess    `json:"keyAccess"`
+	Policy        *sdk.PolicyObject  `json:"policy,omitempty"`
+	Attributes    []string           `json:"attributes"`
+	Assertions    []sdk.Assertion    `json:"assertions,omitempty"`
+	Method        sdk.Method         `json:"method"`
+	Integrity     ZTDFIntegrity      `json:"integrity"`
+	Segments      []ZTDFSegmentSizes `json:"segments"`
+}
+
+// ZTDFIntegrity summarises the manifest integrity information.
+type ZTDFIntegrity struct {
+	RootSignatureAlg            string `json:"rootSignatureAlg"`
+	SegmentHashAlg              string `json:"segmentHashAlg"`
+	DefaultSegmentSize          int64  `json:"segmentSizeDefault"`
+	DefaultEncryptedSegmentSize int64  `json:"encryptedSegmentSizeDefault"`
+	PlaintextSize               int64  `json:"plaintextSize"`
+}
+
+// ZTDFSegmentSizes is the plaintext and encrypted size of one payload segment.
+type ZTDFSegmentSizes struct {
+	Size          int64 `json:"size"`
+	EncryptedSize int64 `json:"encryptedSize"`
+}
+
+// Inspect parses the header or manifest of the TDF in r. No key is
+// requested from KAS, so it works for files the caller cannot decrypt.
+func Inspect(r io.ReadSeeker) (*Inspection, error) {
+	format, err := DetectFormat(r)
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine input size: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+
+	result := &Inspection{Format: string(format), Size: size}
+	if format == FormatNano {
+		result.Nano, err = inspectNano(r)
+	} else {
+		result.ZTDF, err = inspectZTDF(r, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PolicyAttributes returns the attribute values of the policy and whether
+// they could be read without KAS, which is not the case for a nanoTDF
+// policy that is not stored in plaintext.
+func (i *Inspection) PolicyAttributes() ([]string, bool) {
+	switch {
+	case i.ZTDF != nil:
+		return i.ZTDF.Attributes, true
+	case i.Nano != nil && i.Nano.PolicyType == string(PolicyPlaintext):
+		return i.Nano.Attributes, true
+	}
+	return nil, false
+}
+
+// NanoPolicy is how a nanoTDF header stores and binds its policy, using the
+// same names as NanoInfo.
+type NanoPolicy struct {
+	Mode    string
+	Binding string
+}
+
+// String renders the policy as e.g. "encrypted policy, ECDSA binding".
+func (p NanoPolicy) String() string {
+	return p.Mode + " policy, " + p.Binding + " binding"
+}
+
+// ReadNanoPolicy reads the nanoTDF header at the start of r to report its
+// policy mode and binding, then rewinds r to the beginning.
+func ReadNanoPolicy(r io.ReadSeeker) (NanoPolicy, error) {
+	header, _, err := sdk.NewNanoTDFHeaderFromReader(r)
+	if err != nil {
+		return NanoPolicy{}, fmt.Errorf("failed to read nanoTDF header: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return NanoPolicy{}, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+	return NanoPolicy{Mode: PolicyModeName(header.PolicyMode), Binding: bindingName(&header)}, nil
+}
+
+func bindingName(header *sdk.NanoTDFHeader) string {
+	if header.IsEcdsaBindingEnabled() {
+		return "ECDSA"
+	}
+	return "GMAC"
+}
+
+func inspectNano(r io.Reader) (*NanoInfo, error) {
+	header, headerSize, err := sdk.NewNanoTDFHeaderFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nanoTDF header: %w", err)
+	}
+
+	kas := header.GetKasURL()
+	info := &NanoInfo{
+		KasURL:        kas.KASURI(),
+		PolicyType:    PolicyModeName(header.PolicyMode),
+		PolicyBinding: bindingName(&header),
+		HeaderSize:    headerSize,
+	}
+	if id, err := kas.GetIdentifier(); err == nil {
+		info.KasKeyID = id
+	}
+	if curve, err := header.ECCurve(); err == nil {
+		info.ECCMode = curve.Params().Name
+	}
+	if tagSize, err := sdk.SizeOfAuthTagForCipher(header.GetCipher()); err == nil {
+		info.Cipher = fmt.Sprintf("AES-256-GCM (%d-bit tag)", tagSize*8)
+	}
+	if info.PolicyBindingValid, err = header.VerifyPolicyBinding(); err != nil {
+		return nil, fmt.Errorf("failed to verify policy binding: %w", err)
+	}
+
+	if header.PolicyMode == sdk.NanoTDFPolicyModePlainText {
+		var policy sdk.PolicyObject
+		if err := json.Unmarshal(header.PolicyBody, &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse plaintext policy: %w", err)
+		}
+		for _, a := range policy.Body.DataAttributes {
+			info.Attributes = append(info.Attributes, a.Attribute)
+		}
+	}
+	return info, nil
+}
+
+func inspectZTDF(r io.ReadSeeker, size int64) (*ZTDFInfo, error) {
+	ra, err := readerAt(r)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZTDF archive: %w", err)
+	}
+	f, err := zr.Open(ztdfManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("ZTDF archive has no manifest: %w", err)
+	}
+	defer f.Close()
+
+	var manifest sdk.Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse ZTDF manifest: %w", err)
+	}
+
+	integrity := manifest.EncryptionInformation.IntegrityInformation
+	info := &ZTDFInfo{
+		SchemaVersion: manifest.TDFVersion,
+		MimeType:      manifest.Payload.MimeType,
+		KeyAccess:     manifest.EncryptionInformation.KeyAccessObjs,
+		Attributes:    []string{},
+		Assertions:    manifest.Assertions,
+		Method:        manifest.EncryptionInformation.Method,
+		Integrity: ZTDFIntegrity{
+			RootSignatureAlg:            integrity.RootSignature.Algorithm,
+			SegmentHashAlg:              integrity.SegmentHashAlgorithm,
+			DefaultSegmentSize:          integrity.DefaultSegmentSize,
+			DefaultEncryptedSegmentSize: integrity.DefaultEncryptedSegSize,
+		},
+		Segments: make([]ZTDFSegmentSizes, len(integrity.Segments)),
+	}
+	for i, s := range integrity.Segments {
+		info.Segments[i] = ZTDFSegmentSizes{Size: s.Size, EncryptedSize: s.EncryptedSize}
+		info.Integrity.PlaintextSize += s.Size
+	}
+
+	// The policy is base64 encoded JSON in the manifest
+	policyJSON, err := base64.StdEncoding.DecodeString(manifest.EncryptionInformation.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ZTDF policy: %w", err)
+	}
+	var policy sdk.PolicyObject
+	if err := json.Unmarshal(policyJSON, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse ZTDF policy: %w", err)
+	}
+	info.Policy = &policy
+	for _, a := range policy.Body.DataAttributes {
+		info.Attributes = append(info.Attributes, a.Attribute)
+	}
+	return info, nil
+}
+
+// PolicyModeName returns the name used in inspect output for a nanoTDF
+// policy mode.
+func PolicyModeName(mode sdk.PolicyType) string {
+	switch mode {
+	case sdk.NanoTDFPolicyModeRemote:
+		return "remote"
+	case sdk.NanoTDFPolicyModePlainText:
+		return "plaintext"
+	case sdk.NanoTDFPolicyModeEncrypted:
+		return "encrypted"
+	case sdk.NanoTDFPolicyModeEncryptedPolicyKeyAccess:
+		return "encrypted-policy-key-access"
+	default:
+		return fmt.Sprintf("unknown(%d)", mode)
+	}
+}
@@ -0,0 +1,96 @@
+package tdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// PolicyMode selects how a nanoTDF stores its policy in the header.
+type PolicyMode string
+
+const (
+	// PolicyEncrypted encrypts the policy with the payload key so attribute
+	// values are not readable from the header. This is the default.
+	PolicyEncrypted PolicyMode = "encrypted"
+	// PolicyPlaintext stores the policy as readable JSON in the header.
+	PolicyPlaintext PolicyMode = "plaintext"
+)
+
+// Binding selects how a nanoTDF policy is bound to its key.
+type Binding string
+
+const (
+	// BindingECDSA signs the policy with the ephemeral key. This is the
+	// default.
+	BindingECDSA Binding = "ecdsa"
+	// BindingGMAC binds the policy with a truncated digest, which is smaller
+	// but not a signature.
+	BindingGMAC Binding = "gmac"
+)
+
+// ErrRemotePolicyUnsupported is returned when a remote nanoTDF policy is
+// requested; the OpenTDF SDK cannot yet create them, so the mode is
+// rejected when parsed.
+var ErrRemotePolicyUnsupported = errors.New("remote nanoTDF policies are not supported by the OpenTDF SDK; use encrypted or plaintext")
+
+// ParsePolicyMode converts a user supplied policy mode. An empty string
+// returns the zero value, which Encrypt treats as PolicyEncrypted.
+func ParsePolicyMode(s string) (PolicyMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case "encrypted", "embedded-encrypted":
+		return PolicyEncrypted, nil
+	case "plaintext", "plain", "embedded-plaintext":
+		return PolicyPlaintext, nil
+	case "remote":
+		return "", ErrRemotePolicyUnsupported
+	default:
+		return "", fmt.Errorf("unsupported policy mode %q (expected \"encrypted\" or \"plaintext\")", s)
+	}
+}
+
+// ParseBinding converts a user supplied policy binding. An empty string
+// returns the zero value, which Encrypt treats as BindingECDSA.
+func ParseBinding(s string) (Binding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case "ecdsa":
+		return BindingECDSA, nil
+	case "gmac":
+		return BindingGMAC, nil
+	default:
+		return "", fmt.Errorf("unsupported policy binding %q (expected \"ecdsa\" or \"gmac\")", s)
+	}
+}
+
+// applyNanoPolicy configures the policy mode and binding on a nanoTDF
+// config, defaulting to an encrypted, ECDSA-bound policy.
+func applyNanoPolicy(config *sdk.NanoTDFConfig, mode PolicyMode, binding Binding) error {
+	switch mode {
+	case "", PolicyEncrypted:
+		if err := config.SetPolicyMode(sdk.NanoTDFPolicyModeEncrypted); err != nil {
+			return fmt.Errorf("failed to set policy mode: %w", err)
+		}
+	case PolicyPlaintext:
+		if err := config.SetPolicyMode(sdk.NanoTDFPolicyModePlainText); err != nil {
+			return fmt.Errorf("failed to set policy mode: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported policy mode %q", mode)
+	}
+
+	switch binding {
+	case "", BindingECDSA:
+		config.EnableECDSAPolicyBinding()
+	case BindingGMAC:
+		// GMAC is the SDK default when ECDSA is not enabled
+	default:
+		return fmt.Errorf("unsupported policy binding %q", binding)
+	}
+	return nil
+}
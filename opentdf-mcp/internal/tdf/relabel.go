@@ -0,0 +1,229 @@
+package tdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// RelabelOptions describes the policy change made by Relabel.
+type RelabelOptions struct {
+	// Add and Remove edit the existing attribute list. They only work on a
+	// readable source policy, i.e. ZTDF or a nanoTDF with a plaintext
+	// policy; an encrypted nanoTDF policy can only be replaced with Set.
+	Add    []string
+	Remove []string
+	// Set replaces the attribute list entirely when Replace is true. An
+	// empty Set with Replace removes every attribute.
+	Set     []string
+	Replace bool
+	// KasURL is used when the source does not name a KAS.
+	KasURL string
+}
+
+// RelabelResult reports the old and new policy of a relabelled TDF.
+type RelabelResult struct {
+	Format Format `json:"format"`
+	// OldAttributes is nil when the source policy could not be read.
+	OldAttributes []string       `json:"oldAttributes"`
+	NewAttributes []string       `json:"newAttributes"`
+	Changes       []PolicyChange `json:"changes"`
+	PlaintextSize int64          `json:"plaintextSize"`
+	EncryptedSize int64          `json:"encryptedSize"`
+}
+
+// PolicyChange is one row of an old-versus-new policy comparison.
+type PolicyChange struct {
+	Attribute string `json:"attribute"`
+	// Change is "kept", "added" or "removed".
+	Change string `json:"change"`
+}
+
+// Relabel decrypts the TDF in r and re-encrypts it to w with an edited
+// attribute policy. The plaintext is only ever held in memory. The format,
+// KAS, nanoTDF policy mode and binding, and the ZTDF MIME type and
+// encrypted metadata are carried over from the source. ZTDF assertions are
+// bound to the old payload and are not copied.
+func Relabel(ctx context.Context, client *sdk.SDK, w io.Writer, r io.ReadSeeker, opts RelabelOptions) (RelabelResult, error) {
+	info, err := Inspect(r)
+	if err != nil {
+		return RelabelResult{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return RelabelResult{}, fmt.Errorf("failed to seek to beginning: %w", err)
+	}
+
+	format := Format(info.Format)
+	encOpts, oldAttrs, err := sourceOptions(info, opts.KasURL)
+	if err != nil {
+		return RelabelResult{}, err
+	}
+
+	newAttrs, err := editAttributes(oldAttrs, opts)
+	if err != nil {
+		return RelabelResult{}, err
+	}
+	encOpts.Attributes = newAttrs
+
+	// Decrypt into memory only; the buffer is wiped once re-encrypted
+	var plaintext bytes.Buffer
+	defer func() { clear(plaintext.Bytes()) }()
+	if format == FormatZTDF {
+		tdfReader, err := client.LoadTDF(r)
+		if err != nil {
+			return RelabelResult{}, fmt.Errorf("failed to load TDF: %w", err)
+		}
+		if _, err := io.Copy(&plaintext, tdfReader); err != nil && !errors.Is(err, io.EOF) {
+			return RelabelResult{}, fmt.Errorf("failed to decrypt TDF: %w", err)
+		}
+		metadata, err := tdfReader.UnencryptedMetadata()
+		if err != nil {
+			return RelabelResult{}, fmt.Errorf("failed to read TDF metadata: %w", err)
+		}
+		encOpts.Metadata = string(metadata)
+	} else if err := Decrypt(ctx, client, &plaintext, r, format); err != nil {
+		return RelabelResult{}, err
+	}
+
+	result, err := Encrypt(client, w, bytes.NewReader(plaintext.Bytes()), encOpts)
+	if err != nil {
+		return RelabelResult{}, err
+	}
+
+	return RelabelResult{
+		Format:        format,
+		OldAttributes: oldAttrs,
+		NewAttributes: newAttrs,
+		Changes:       DiffPolicies(oldAttrs, newAttrs),
+		PlaintextSize: result.PlaintextSize,
+		EncryptedSize: result.EncryptedSize,
+	}, nil
+}
+
+// sourceOptions derives EncryptOptions that reproduce the inspected TDF's
+// format, KAS, nanoTDF policy mode and binding, and ZTDF MIME type, along
+// with its attributes. The attributes are nil when the policy is encrypted
+// and cannot be read. kasURL is used when the source names no KAS.
+func sourceOptions(info *Inspection, kasURL string) (EncryptOptions, []string, error) {
+	opts := EncryptOptions{Format: Format(info.Format), KasURL: kasURL}
+	var attrs []string
+	var err error
+	switch opts.Format {
+	case FormatNano:
+		if opts.PolicyMode, err = ParsePolicyMode(info.Nano.PolicyType); err != nil {
+			return EncryptOptions{}, nil, err
+		}
+		if opts.Binding, err = ParseBinding(info.Nano.PolicyBinding); err != nil {
+			return EncryptOptions{}, nil, err
+		}
+		if info.Nano.KasURL != "" {
+			opts.KasURL = info.Nano.KasURL
+		}
+		if opts.PolicyMode == PolicyPlaintext {
+			attrs = normalizeAttributes(info.Nano.Attributes)
+		}
+	case FormatZTDF:
+		opts.MimeType = info.ZTDF.MimeType
+		if len(info.ZTDF.KeyAccess) > 0 && info.ZTDF.KeyAccess[0].KasURL != "" {
+			opts.KasURL = info.ZTDF.KeyAccess[0].KasURL
+		}
+		attrs = normalizeAttributes(info.ZTDF.Attributes)
+	}
+	return opts, attrs, nil
+}
+
+// editAttributes applies opts to the source attributes. oldAttrs is nil
+// when the source policy is encrypted.
+func editAttributes(oldAttrs []string, opts RelabelOptions) ([]string, error) {
+	if opts.Replace {
+		if len(opts.Add) > 0 || len(opts.Remove) > 0 {
+			return nil, fmt.Errorf("cannot combine a replacement attribute list with add or remove")
+		}
+		return normalizeAttributes(opts.Set), nil
+	}
+	if len(opts.Add) == 0 && len(opts.Remove) == 0 {
+		return nil, fmt.Errorf("no attributes to add, remove or set")
+	}
+	if oldAttrs == nil {
+		return nil, fmt.Errorf("adding and removing attributes only works on ZTDF and plaintext-policy nanoTDFs; this nanoTDF's policy is encrypted, so its current attributes cannot be read: give the complete new policy with set instead")
+	}
+
+	attrs := slices.Clone(oldAttrs)
+	for _, a := range normalizeAttributes(opts.Remove) {
+		// FQNs are case-insensitive on the platform
+		i := slices.IndexFunc(attrs, func(b string) bool { return strings.EqualFold(a, b) })
+		if i < 0 {
+			return nil, fmt.Errorf("attribute %s is not in the current policy", a)
+		}
+		attrs = slices.Delete(attrs, i, i+1)
+	}
+	attrs = normalizeAttributes(append(attrs, opts.Add...))
+	if slices.Equal(attrs, oldAttrs) {
+		return nil, fmt.Errorf("the policy already has exactly these attributes")
+	}
+	return attrs, nil
+}
+
+// normalizeAttributes returns a sorted copy of attrs without duplicates.
+// The result is never nil.
+func normalizeAttributes(attrs []string) []string {
+	out := make([]string, 0, len(attrs))
+	out = append(out, attrs...)
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// DiffPolicies lists every attribute in either policy and whether it was
+// kept, added or removed. When oldAttrs is nil (unreadable) every new
+// attribute is reported as added.
+func DiffPolicies(oldAttrs, newAttrs []string) []PolicyChange {
+	var changes []PolicyChange
+	for _, a := range normalizeAttributes(append(slices.Clone(oldAttrs), newAttrs...)) {
+		inOld, inNew := slices.Contains(oldAttrs, a), slices.Contains(newAttrs, a)
+		switch {
+		case inOld && inNew:
+			changes = append(changes, PolicyChange{Attribute: a, Change: "kept"})
+		case inNew:
+			changes = append(changes, PolicyChange{Attribute: a, Change: "added"})
+		default:
+			changes = append(changes, PolicyChange{Attribute: a, Change: "removed"})
+		}
+	}
+	return changes
+}
+
+// WritePolicyDiff prints the old and new policy side by side, one attribute
+// per row.
+func WritePolicyDiff(w io.Writer, result RelabelResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ATTRIBUTE\tOLD\tNEW\tCHANGE")
+	for _, c := range result.Changes {
+		before, after := "x", "x"
+		switch c.Change {
+		case "added":
+			before = "-"
+			if result.OldAttributes == nil {
+				before = "?"
+			}
+		case "removed":
+			after = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Attribute, before, after, c.Change)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if result.OldAttributes == nil {
+		_, err := fmt.Fprintln(w, "The old policy was encrypted, so its attributes are unknown (?).")
+		return err
+	}
+	return nil
+}
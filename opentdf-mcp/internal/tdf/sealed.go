@@ -0,0 +1,105 @@
+package tdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/opentdf/platform/sdk"
+)
+
+// SealedPrefix marks a value in a partially encrypted document (a CSV cell,
+// a JSON field, a memo portion) that holds a base64 encoded nanoTDF.
+const SealedPrefix = "ntdf:"
+
+// Redacted replaces sealed values the caller is not entitled to open.
+const Redacted = "[REDACTED]"
+
+// IsSealed reports whether s is a value produced by Sealer.Seal.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, SealedPrefix)
+}
+
+// Sealer encrypts many small values into inline nanoTDFs. Values with the
+// same attributes share one nanoTDF collection, so they carry the same
+// header and a reader needs only one KAS rewrap per distinct policy.
+type Sealer struct {
+	client  *sdk.SDK
+	kasURL  string
+	mode    PolicyMode
+	binding Binding
+	configs map[string]*sdk.NanoTDFConfig
+}
+
+// NewSealer returns a Sealer that wraps keys with the KAS at kasURL using
+// the given nanoTDF policy mode and binding (zero values select the
+// defaults, as for Encrypt).
+func NewSealer(client *sdk.SDK, kasURL string, mode PolicyMode, binding Binding) *Sealer {
+	return &Sealer{client: client, kasURL: kasURL, mode: mode, binding: binding, configs: map[string]*sdk.NanoTDFConfig{}}
+}
+
+// Seal encrypts value under attrs and returns it as SealedPrefix followed by
+// the standard base64 nanoTDF.
+func (s *Sealer) Seal(value []byte, attrs []string) (string, error) {
+	attrs = normalizeAttributes(attrs)
+	key := strings.Join(attrs, "\n")
+	config, ok := s.configs[key]
+	if !ok {
+		var err error
+		if config, err = newNanoConfig(s.client, attrs, s.kasURL, s.mode, s.binding); err != nil {
+			return "", err
+		}
+		config.EnableCollection()
+		s.configs[key] = config
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.client.CreateNanoTDF(&buf, bytes.NewReader(value), *config); err != nil {
+		return "", fmt.Errorf("failed to create nanoTDF: %w", err)
+	}
+	return SealedPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Opener decrypts values produced by Sealer. A policy that KAS denies once
+// is remembered, so the other values sharing its header are redacted without
+// another round trip. Create the client with sdk.WithStoreCollectionHeaders
+// so that allowed collections are also unwrapped only once.
+type Opener struct {
+	client *sdk.SDK
+	denied map[string]bool
+}
+
+// NewOpener returns an Opener using client.
+func NewOpener(client *sdk.SDK) *Opener {
+	return &Opener{client: client, denied: map[string]bool{}}
+}
+
+// Open decrypts a sealed value. ok is false when KAS denied access, in which
+// case the caller should show Redacted. Any other failure, such as an
+// unreachable platform or a corrupt value, is returned as an error.
+func (o *Opener) Open(ctx context.Context, sealed string) (plaintext []byte, ok bool, err error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, SealedPrefix))
+	if err != nil {
+		return nil, false, fmt.Errorf("sealed value is not valid base64: %w", err)
+	}
+	_, headerSize, err := sdk.NewNanoTDFHeaderFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, &UnsupportedFormatError{Detected: "a truncated or corrupt nanoTDF", Err: err}
+	}
+	header := string(data[:headerSize])
+	if o.denied[header] {
+		return nil, false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := Decrypt(ctx, o.client, &buf, bytes.NewReader(data), FormatNano); err != nil {
+		if ClassifyError(err) == KindDenied {
+			o.denied[header] = true
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
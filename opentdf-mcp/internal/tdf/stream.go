@@ -0,0 +1,132 @@
+package tdf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SeekableInput returns f as an io.ReadSeeker suitable for Encrypt. Regular
+// files are used in place. Pipes and terminals (typically stdin) are spooled
+// to an owner-only temporary file so that large inputs are never buffered in
+// memory. The returned cleanup function must be called once the reader is no
+// longer needed; it never closes f itself.
+func SeekableInput(f *os.File) (io.ReadSeeker, func(), error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat input: %w", err)
+	}
+	if info.Mode().IsRegular() {
+		return f, func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "opentdf-input-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	if _, err := io.Copy(tmp, f); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to seek spool file: %w", err)
+	}
+	return tmp, cleanup, nil
+}
+
+// HumanSize formats a byte count using binary units, e.g. "1.5 GiB".
+func HumanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// OutputFile is written next to its destination and renamed into place by
+// Commit, so a failed write neither truncates nor removes a file that is
+// already there, such as an earlier good output.
+type OutputFile struct {
+	*os.File
+	path string
+	perm os.FileMode
+}
+
+// CreateOutput starts an OutputFile for path. Until Commit the temporary
+// file is owner-only; perm is applied when it is renamed into place, e.g.
+// 0600 for decrypted plaintext so it is never left world readable. Discard
+// should be deferred to drop the temporary file on failure.
+func CreateOutput(path string, perm os.FileMode) (*OutputFile, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return &OutputFile{File: tmp, path: path, perm: perm}, nil
+}
+
+// Commit closes the file and renames it to its destination.
+func (f *OutputFile) Commit() error {
+	if err := f.File.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := os.Chmod(f.Name(), f.perm); err != nil {
+		return fmt.Errorf("failed to set output permissions: %w", err)
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// Discard closes and removes the temporary file. After Commit it has
+// nothing left to remove.
+func (f *OutputFile) Discard() {
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// WriteOutput writes data to path through an OutputFile.
+func WriteOutput(path string, data []byte, perm os.FileMode) error {
+	f, err := CreateOutput(path, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Discard()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return f.Commit()
+}
+
+// CheckOutput refuses an output path that names the input file, which
+// would be replaced by its own encryption or decryption. Paths that do not
+// exist yet are fine.
+func CheckOutput(input, output string) error {
+	if input == "" || output == "" {
+		return nil
+	}
+	in, err := os.Stat(input)
+	if err != nil {
+		return nil
+	}
+	out, err := os.Stat(output)
+	if err != nil {
+		return nil
+	}
+	if os.SameFile(in, out) {
+		return fmt.Errorf("output %s is the input file; choose another output path", output)
+	}
+	return nil
+}
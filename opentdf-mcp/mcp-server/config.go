@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"strconv"
 	"github.com/joho/godotenv"
 )
 
@@ -36,3 +37,16 @@ func getClientSecret() string {
 func getAgentJWT() string {
 	return os.Getenv("OPENTDF_AGENT_JWT")
 }
+
+// defaultMaxInlineSize caps how many encrypted bytes the encrypt tool will
+// return inline as base64 when no output path is given.
+const defaultMaxInlineSize = 4 * 1024 * 1024
+
+func getMaxInlineSize() int64 {
+	if v := os.Getenv("OPENTDF_MCP_MAX_INLINE_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultMaxInlineSize
+}
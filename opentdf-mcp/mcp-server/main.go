@@ -5,16 +5,29 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/opentdf/opentdf-mcp/internal/access"
+	"github.com/opentdf/opentdf-mcp/internal/agent"
+	"github.com/opentdf/opentdf-mcp/internal/batch"
+	"github.com/opentdf/opentdf-mcp/internal/csvtdf"
+	"github.com/opentdf/opentdf-mcp/internal/doctdf"
+	"github.com/opentdf/opentdf-mcp/internal/memotdf"
+	"github.com/opentdf/opentdf-mcp/internal/suggest"
+	"github.com/opentdf/opentdf-mcp/internal/tdf"
 	"github.com/opentdf/platform/protocol/go/policy/attributes"
 	"github.com/opentdf/platform/protocol/go/policy/namespaces"
 	"github.com/opentdf/platform/sdk"
@@ -27,29 +40,304 @@ type EncryptToolInput struct {
 	Data         string   `json:"data,omitempty" jsonschema:"Literal data to encrypt (mutually exclusive with input)"`
 	Attributes   []string `json:"attributes" jsonschema:"Data attributes (FQNs) to apply during encryption"`
 	Output       string   `json:"output,omitempty" jsonschema:"Output file path (optional returns base64 if not specified)"`
+	Format       string   `json:"format,omitempty" jsonschema:"Container format: nano (default, compact nanoTDF) or ztdf (zip TDF with full manifest for large or rich documents)"`
+	MimeType     string   `json:"mimeType,omitempty" jsonschema:"MIME type recorded in the ZTDF manifest (optional detected from the input when omitted)"`
+	PolicyMode   string   `json:"policyMode,omitempty" jsonschema:"nanoTDF policy mode: encrypted (default, hides attribute values in the header) or plaintext"`
+	Binding      string   `json:"binding,omitempty" jsonschema:"nanoTDF policy binding: ecdsa (default) or gmac"`
 	ClientID     string   `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
 	ClientSecret string   `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+	// Fields switches to field-level encryption of a JSON or YAML document
+	Fields []doctdf.Field `json:"fields,omitempty" jsonschema:"JSONPath selectors (e.g. $.crew[*].name), each with its own attribute FQNs. Only the selected values of the JSON or YAML input are encrypted, as inline nanoTDF strings; 'attributes' apply to every selected value"`
+	// Memo switches to portion-by-portion encryption of memo markdown
+	Memo     bool   `json:"memo,omitempty" jsonschema:"Encrypt memo markdown (as consumed by memo-mcp) portion by portion: each paragraph or bullet marked (S), (TS), etc. is encrypted under the attributes of its marking, while (U) portions and the frontmatter stay readable"`
+	Markings string `json:"markings,omitempty" jsonschema:"Path to a YAML or JSON table mapping portion markings, frontmatter classifications, flights and tags to attributes (optional; defaults map S and TS to the demo.usaf.mil classification attributes, flights to flight_id and the maintenance tag to functional)"`
+	// MemoSource derives the attributes from memo frontmatter
+	MemoSource string `json:"memoSource,omitempty" jsonschema:"Path to memo markdown whose frontmatter sets the attributes: its classification, flight identifiers (e.g. RCH2532101) and functional tags are mapped to FQNs through the 'markings' table. 'input' must be that markdown or a PDF rendered from it. Explicit 'attributes' are refused unless they match the frontmatter"`
+	// OverrideMarkings skips the classification-marking guard
+	OverrideMarkings bool `json:"overrideMarkings,omitempty" jsonschema:"Encrypt even though the plaintext carries classification banners or portion markings (e.g. TOP SECRET//, (S)) above what 'attributes' protect. Every override is logged with the agent identity; only set it after a human has confirmed the markings are wrong"`
+	// NoVerify skips the platform check of the attribute FQNs
+	NoVerify bool `json:"noVerify,omitempty" jsonschema:"Skip confirming that every attribute FQN names an existing, active attribute value on the platform (for offline use). FQNs are still normalized to lower case and parsed"`
 }
 
 type EncryptToolOutput struct {
-	Success    bool   `json:"success"`
-	OutputFile string `json:"outputFile"`
-	Message    string `json:"message,omitempty"`
-	Error      string `json:"error,omitempty"`
+	Success       bool   `json:"success"`
+	OutputFile    string `json:"outputFile,omitempty"`
+	EncryptedData string `json:"encryptedData,omitempty"`
+	Format        string `json:"format,omitempty"`
+	PlaintextSize int64  `json:"plaintextSize,omitempty"`
+	EncryptedSize int64  `json:"encryptedSize,omitempty"`
+	Message       string `json:"message,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// Document and SealedFields are set by field-level encryption
+	Document     string               `json:"document,omitempty"`
+	SealedFields []doctdf.SealedField `json:"sealedFields,omitempty"`
+	// SealedPortions and Warnings are set by memo encryption; Warnings also
+	// records an overridden classification-marking guard or a caller locked
+	// out of its own output
+	SealedPortions []memotdf.Portion `json:"sealedPortions,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+	// Derivation is set when the attributes come from memo frontmatter
+	Derivation *memotdf.Derivation `json:"derivation,omitempty"`
 }
 
 // DecryptToolInput defines the input for the decrypt tool
 type DecryptToolInput struct {
 	Input        string `json:"input" jsonschema:"Path to encrypted file or base64 encoded data"`
-	Output       string `json:"output,omitempty" jsonschema:"Output file path (optional returns plaintext if not specified)"`
+	Output       string `json:"output,omitempty" jsonschema:"Output file path (optional returns plaintext if not specified). When set the plaintext is written with owner-only permissions and only metadata is returned"`
 	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
 	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
 }
 
 type DecryptToolOutput struct {
-	Success      bool   `json:"success"`
+	Success       bool   `json:"success"`
 	DecryptedData string `json:"decryptedData,omitempty"`
-	Error        string `json:"error,omitempty"`
+	OutputFile    string `json:"outputFile,omitempty"`
+	Size          int64  `json:"size,omitempty"`
+	SHA256        string `json:"sha256,omitempty"`
+	Format        string `json:"format,omitempty"`
+	PolicyMode    string `json:"policyMode,omitempty"`
+	PolicyBinding string `json:"policyBinding,omitempty"`
+	// DetectedFormat describes unsupported input, e.g. "plaintext" or
+	// "a base64-encoded nanoTDF"
+	DetectedFormat string `json:"detectedFormat,omitempty"`
+	// OpenedFields and RedactedFields list the sealed values of a JSON or
+	// YAML document by path
+	OpenedFields   []string `json:"openedFields,omitempty"`
+	RedactedFields []string `json:"redactedFields,omitempty"`
+	Error          string   `json:"error,omitempty"`
+	// OpenedPortions and RedactedPortions list the sealed portions of a
+	// memo by line and marking
+	OpenedPortions   []memotdf.Portion `json:"openedPortions,omitempty"`
+	RedactedPortions []memotdf.Portion `json:"redactedPortions,omitempty"`
+	// ErrorKind classifies a failed decrypt: denied, auth_error,
+	// network_error, malformed, integrity_error or error
+	ErrorKind string `json:"errorKind,omitempty"`
+	// MissingAttributes names, for a denied decrypt, the policy attribute
+	// values the caller is not entitled to
+	MissingAttributes []access.Gap `json:"missingAttributes,omitempty"`
+}
+
+// InspectToolInput defines the input for the inspect_tdf tool
+type InspectToolInput struct {
+	Input string `json:"input" jsonschema:"Path to a TDF file or base64 encoded TDF data"`
+}
+
+type InspectToolOutput struct {
+	Success    bool            `json:"success"`
+	Inspection *tdf.Inspection `json:"inspection,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// RelabelToolInput defines the input for the relabel tool
+type RelabelToolInput struct {
+	Input            string   `json:"input" jsonschema:"Path to a TDF file or base64 encoded TDF data"`
+	Output           string   `json:"output,omitempty" jsonschema:"Output file path (optional returns the relabeled TDF as base64 if not specified). May equal input to replace it"`
+	AddAttributes    []string `json:"addAttributes,omitempty" jsonschema:"Attribute FQNs to add to the policy (ZTDF and plaintext-policy nanoTDF only)"`
+	RemoveAttributes []string `json:"removeAttributes,omitempty" jsonschema:"Attribute FQNs to remove from the policy (ZTDF and plaintext-policy nanoTDF only)"`
+	SetAttributes    []string `json:"setAttributes,omitempty" jsonschema:"Replace the whole policy with these attribute FQNs (required for nanoTDFs with encrypted policies)"`
+	ClientID         string   `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret     string   `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type RelabelToolOutput struct {
+	Success       bool               `json:"success"`
+	OutputFile    string             `json:"outputFile,omitempty"`
+	EncryptedData string             `json:"encryptedData,omitempty"`
+	Format        string             `json:"format,omitempty"`
+	OldAttributes []string           `json:"oldAttributes,omitempty"`
+	NewAttributes []string           `json:"newAttributes,omitempty"`
+	Changes       []tdf.PolicyChange `json:"changes,omitempty"`
+	Error         string             `json:"error,omitempty"`
+}
+
+// ConvertToolInput defines the input for the convert tool
+type ConvertToolInput struct {
+	Input        string   `json:"input" jsonschema:"Path to a TDF file or base64 encoded TDF data"`
+	Format       string   `json:"format" jsonschema:"Target container format: nano or ztdf"`
+	Output       string   `json:"output,omitempty" jsonschema:"Output file path (optional returns the converted TDF as base64 if not specified)"`
+	Attributes   []string `json:"attributes,omitempty" jsonschema:"Attribute FQNs for the target policy (optional copies the source policy; required when a nanoTDF policy is encrypted). Must include every source attribute"`
+	PolicyMode   string   `json:"policyMode,omitempty" jsonschema:"nanoTDF target policy mode: encrypted (default) or plaintext"`
+	Binding      string   `json:"binding,omitempty" jsonschema:"nanoTDF target policy binding: ecdsa (default) or gmac"`
+	ClientID     string   `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string   `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+	// AllowUnverified is the explicit override for an encrypted source policy
+	AllowUnverified bool `json:"allowUnverified,omitempty" jsonschema:"Accept 'attributes' for a source nanoTDF whose policy is encrypted and cannot be checked (otherwise refused, since the target could be weaker)"`
+	// AllowPlaintextPolicy is the explicit override for disclosing an encrypted source policy
+	AllowPlaintextPolicy bool `json:"allowPlaintextPolicy,omitempty" jsonschema:"Accept a ZTDF target for a source nanoTDF whose policy is encrypted (otherwise refused, since the ZTDF manifest stores the policy unencrypted and would disclose its attribute values)"`
+}
+
+type ConvertToolOutput struct {
+	Success          bool     `json:"success"`
+	OutputFile       string   `json:"outputFile,omitempty"`
+	EncryptedData    string   `json:"encryptedData,omitempty"`
+	SourceFormat     string   `json:"sourceFormat,omitempty"`
+	TargetFormat     string   `json:"targetFormat,omitempty"`
+	SourceAttributes []string `json:"sourceAttributes,omitempty"`
+	TargetAttributes []string `json:"targetAttributes,omitempty"`
+	PolicyVerified   bool     `json:"policyVerified,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// EncryptCSVToolInput defines the input for the encrypt_csv tool
+type EncryptCSVToolInput struct {
+	Input        string `json:"input" jsonschema:"Path to the CSV file to encrypt (first row is the header)"`
+	Rules        string `json:"rules" jsonschema:"Path to a YAML or JSON rules file: clear columns, cell or row mode, and attributes keyed on column names or cell values"`
+	Output       string `json:"output,omitempty" jsonschema:"Output file path (optional returns the encrypted CSV text if not specified)"`
+	PolicyMode   string `json:"policyMode,omitempty" jsonschema:"nanoTDF policy mode for each encrypted value: encrypted (default) or plaintext"`
+	Binding      string `json:"binding,omitempty" jsonschema:"nanoTDF policy binding: ecdsa (default) or gmac"`
+	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+	// The attribute and marking checks match those of encrypt
+	Markings         string `json:"markings,omitempty" jsonschema:"Path to a marking table (YAML or JSON) mapping classification levels to attribute FQNs for the marking guard"`
+	OverrideMarkings bool   `json:"overrideMarkings,omitempty" jsonschema:"Encrypt even though a value carries classification markings above what its attributes protect. Every override is logged with the agent identity; only set it after a human has confirmed the markings are wrong"`
+	NoVerify         bool   `json:"noVerify,omitempty" jsonschema:"Skip confirming that every rule attribute FQN names an existing, active attribute value on the platform (for offline use)"`
+}
+
+type EncryptCSVToolOutput struct {
+	Success    bool                  `json:"success"`
+	OutputFile string                `json:"outputFile,omitempty"`
+	Data       string                `json:"data,omitempty"`
+	Summary    *csvtdf.EncryptResult `json:"summary,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// DecryptCSVToolInput defines the input for the decrypt_csv tool
+type DecryptCSVToolInput struct {
+	Input        string `json:"input" jsonschema:"Path to a CSV file written by encrypt_csv"`
+	Output       string `json:"output,omitempty" jsonschema:"Output file path (optional returns the decrypted CSV text if not specified); written with owner-only permissions"`
+	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type DecryptCSVToolOutput struct {
+	Success    bool   `json:"success"`
+	OutputFile string `json:"outputFile,omitempty"`
+	Data       string `json:"data,omitempty"`
+	Rows       int    `json:"rows,omitempty"`
+	Opened     int    `json:"opened,omitempty"`
+	Redacted   int    `json:"redacted,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SuggestAttributesToolInput defines the input for the suggest_attributes tool
+type SuggestAttributesToolInput struct {
+	Input        string `json:"input,omitempty" jsonschema:"Path to the plaintext file to scan (mutually exclusive with data)"`
+	Data         string `json:"data,omitempty" jsonschema:"Literal text to scan (mutually exclusive with input)"`
+	Rules        string `json:"rules,omitempty" jsonschema:"Path to a YAML or JSON rules file defining the signals (optional; defaults cover flight IDs, tail numbers, classification banners and portion markings, and maintenance vocabulary)"`
+	NoVerify     bool   `json:"noVerify,omitempty" jsonschema:"Skip checking the suggestions against the attributes defined on the platform"`
+	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type SuggestAttributesToolOutput struct {
+	Success     bool                 `json:"success"`
+	Suggestions []suggest.Suggestion `json:"suggestions"`
+	Warning     string               `json:"warning,omitempty"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// PreviewAccessToolInput defines the input for the preview_access tool
+type PreviewAccessToolInput struct {
+	Attributes   []string `json:"attributes" jsonschema:"Candidate data attribute FQNs, as they would be passed to encrypt"`
+	Entities     []string `json:"entities,omitempty" jsonschema:"Entities to check, each a client ID or 'email:<address>' or 'username:<name>'"`
+	Users        string   `json:"users,omitempty" jsonschema:"Path to a users file such as masterprompt/users.yaml; every client_id in it is checked, labelled with the user's name"`
+	ClientID     string   `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string   `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type PreviewAccessToolOutput struct {
+	Success    bool              `json:"success"`
+	Attributes []string          `json:"attributes,omitempty"`
+	Decisions  []access.Decision `json:"decisions,omitempty"`
+	Table      string            `json:"table,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// GetEntitlementsToolInput defines the input for the get_entitlements tool
+type GetEntitlementsToolInput struct {
+	Identifier             string `json:"identifier,omitempty" jsonschema:"Entity to look up: an email address, username or client ID (optional; defaults to the client credentials this server is using)"`
+	Type                   string `json:"type,omitempty" jsonschema:"Identifier type: email (default), username or clientId"`
+	ComprehensiveHierarchy bool   `json:"comprehensiveHierarchy,omitempty" jsonschema:"Also list the values below an entitled value of a hierarchy attribute (e.g. secret under top-secret)"`
+	ClientID               string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret           string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type GetEntitlementsToolOutput struct {
+	Success      bool                 `json:"success"`
+	Entity       *access.Entity       `json:"entity,omitempty"`
+	Entitlements []access.Entitlement `json:"entitlements,omitempty"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// AccessMatrixToolInput defines the input for the access_matrix tool
+type AccessMatrixToolInput struct {
+	Entities      []string          `json:"entities,omitempty" jsonschema:"Entities (columns), each a client ID or 'email:<address>' or 'username:<name>'"`
+	Users         string            `json:"users,omitempty" jsonschema:"Path to a users file such as masterprompt/users.yaml; every client_id in it becomes a column labelled with the user's name"`
+	Files         []string          `json:"files,omitempty" jsonschema:"TDF files or directories of TDFs (rows). Attributes are read from each policy; nanoTDFs whose policy is encrypted cannot be read and are refused, so give their attribute sets instead"`
+	AttributeSets []access.Resource `json:"attributeSets,omitempty" jsonschema:"Named attribute sets (rows), each {name, attributes}, for data not encrypted yet or TDFs whose policy cannot be read"`
+	ClientID      string            `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret  string            `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type AccessMatrixToolOutput struct {
+	Success bool           `json:"success"`
+	Matrix  *access.Matrix `json:"matrix,omitempty"`
+	Table   string         `json:"table,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// CompareEntitlementsToolInput defines the input for the compare_entitlements tool
+type CompareEntitlementsToolInput struct {
+	Entities               []string `json:"entities" jsonschema:"Two or more entities to compare, each a client ID or 'email:<address>' or 'username:<name>'"`
+	Files                  []string `json:"files,omitempty" jsonschema:"TDF files or directories of TDFs (optional); reports which documents each entity can open that another cannot. Attributes are read from each policy; nanoTDFs whose policy is encrypted are refused, so give their attribute sets instead"`
+	ComprehensiveHierarchy bool     `json:"comprehensiveHierarchy,omitempty" jsonschema:"Also compare the values below an entitled value of a hierarchy attribute"`
+	ClientID               string   `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret           string   `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+	// AttributeSets stand in for documents whose policy cannot be read
+	AttributeSets []access.Resource `json:"attributeSets,omitempty" jsonschema:"Named attribute sets (documents), each {name, attributes}, for data not encrypted yet or nanoTDFs whose policy is encrypted"`
+}
+
+type CompareEntitlementsToolOutput struct {
+	Success    bool               `json:"success"`
+	Comparison *access.Comparison `json:"comparison,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// EncryptBatchToolInput defines the input for the encrypt_batch tool
+type EncryptBatchToolInput struct {
+	InputDir     string `json:"inputDir" jsonschema:"Directory of plaintext files to encrypt"`
+	OutputDir    string `json:"outputDir" jsonschema:"Directory to write encrypted files to (mirrors the input tree)"`
+	Manifest     string `json:"manifest" jsonschema:"Path to a YAML or JSON labels manifest mapping file globs to attribute FQNs"`
+	Format       string `json:"format,omitempty" jsonschema:"Container format: nano or ztdf (optional defaults to the manifest format)"`
+	Force        bool   `json:"force,omitempty" jsonschema:"Re-encrypt files even if their plaintext and attributes are unchanged"`
+	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+	// NoVerify skips the platform check of the manifest attributes
+	NoVerify bool `json:"noVerify,omitempty" jsonschema:"Skip confirming that every manifest attribute names an existing, active attribute value on the platform (for offline use). FQNs are still normalized to lower case and parsed"`
+}
+
+type EncryptBatchToolOutput struct {
+	Success bool           `json:"success"`
+	Results []batch.Result `json:"results,omitempty"`
+	Summary string         `json:"summary,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// DecryptBatchToolInput defines the input for the decrypt_batch tool
+type DecryptBatchToolInput struct {
+	InputDir     string `json:"inputDir" jsonschema:"Directory of TDF files to decrypt"`
+	OutputDir    string `json:"outputDir,omitempty" jsonschema:"Directory to write decrypted files to (optional; omit to only report access)"`
+	Workers      int    `json:"workers,omitempty" jsonschema:"Number of files to decrypt concurrently (default 4)"`
+	ClientID     string `json:"clientId,omitempty" jsonschema:"OAuth client ID for OpenTDF platform authentication"`
+	ClientSecret string `json:"clientSecret,omitempty" jsonschema:"OAuth client secret for OpenTDF platform authentication"`
+}
+
+type DecryptBatchToolOutput struct {
+	Success bool                  `json:"success"`
+	Results []batch.DecryptResult `json:"results,omitempty"`
+	Summary string                `json:"summary,omitempty"`
+	Error   string                `json:"error,omitempty"`
 }
 
 type ListAttributesToolInput struct {
@@ -65,278 +353,1580 @@ type ListAttributesToolOutput struct {
 	Error      string                 `json:"error,omitempty"`
 }
 
-type AttributeInfo struct {
-	Namespace string   `json:"namespace"`
-	Name      string   `json:"name"`
-	Values    []string `json:"values,omitempty"`
-	FQN       string   `json:"fqn"`
+type AttributeInfo struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Values    []string `json:"values,omitempty"`
+	FQN       string   `json:"fqn"`
+}
+
+// validateJWT performs basic validation on JWT claims (mock implementation)
+func validateJWT(claims *agent.Claims) error {
+	if claims == nil {
+		return fmt.Errorf("no claims provided")
+	}
+
+	// Check audience
+	if claims.Aud != "opentdf-mcp" {
+		return fmt.Errorf("invalid audience: expected 'opentdf-mcp', got '%s'", claims.Aud)
+	}
+
+	// Check expiration
+	now := time.Now().Unix()
+	if claims.Exp > 0 && claims.Exp < now {
+		return fmt.Errorf("token expired")
+	}
+
+	// Check issued at
+	if claims.Iat > 0 && claims.Iat > now {
+		return fmt.Errorf("token used before issued")
+	}
+
+	return nil
+}
+
+// logAgentAuthentication logs the agent authentication details
+func logAgentAuthentication() {
+	token := getAgentJWT()
+	if token == "" {
+		log.Println("WARNING: No agent JWT token found. Running without agent authentication.")
+		log.Println("In production, this would require OAuth-issued JWT after user consent.")
+		return
+	}
+
+	claims, err := agent.ParseJWT(token)
+	if err != nil {
+		log.Printf("WARNING: Failed to parse agent JWT: %v\n", err)
+		return
+	}
+
+	if err := validateJWT(claims); err != nil {
+		log.Printf("WARNING: JWT validation failed: %v\n", err)
+		return
+	}
+
+	log.Println("=== Agent Authentication ===")
+	log.Printf("Agent ID: %s\n", claims.Sub)
+	log.Printf("Agent Name: %s\n", claims.AgentName)
+	log.Printf("Issuer: %s\n", claims.Iss)
+	log.Printf("Permissions: %v\n", claims.Permissions)
+	log.Printf("Expires: %s\n", time.Unix(claims.Exp, 0).Format(time.RFC3339))
+	log.Println("NOTE: This is a MOCK JWT for demo purposes.")
+	log.Println("In production, JWT would be issued by OAuth after user consent.")
+	log.Println("===========================")
+}
+
+// getSDKClientMCP creates an authenticated OpenTDF SDK client for MCP
+// If clientID and clientSecret are provided, they take precedence over environment variables
+// Any extra options, such as sdk.WithStoreCollectionHeaders, are applied first
+func getSDKClientMCP(clientID, clientSecret string, extra ...sdk.Option) (*sdk.SDK, error) {
+	platformEndpoint := getPlatformEndpoint()
+
+	// Use provided credentials if available, otherwise fall back to config
+	if clientID == "" {
+		clientID = getClientID()
+	}
+	if clientSecret == "" {
+		clientSecret = getClientSecret()
+	}
+
+	opts := extra
+	if clientID != "" && clientSecret != "" {
+		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
+	} else {
+		opts = append(opts, sdk.WithInsecurePlaintextConn())
+	}
+
+	client, err := sdk.New(platformEndpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SDK client: %w", err)
+	}
+
+	return client, nil
+}
+
+// MCPEncrypt encrypts data with the given attributes
+func MCPEncrypt(ctx context.Context, req *mcp.CallToolRequest, input EncryptToolInput) (*mcp.CallToolResult, EncryptToolOutput, error) {
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	// Validate input: either Input or Data must be provided, but not both
+	if input.Input != "" && input.Data != "" {
+		return nil, EncryptToolOutput{Success: false, Error: "cannot specify both 'input' and 'data' parameters"}, nil
+	}
+	if input.Input == "" && input.Data == "" {
+		return nil, EncryptToolOutput{Success: false, Error: "must specify either 'input' (file path) or 'data' (literal data)"}, nil
+	}
+	if err := tdf.CheckOutput(input.Input, input.Output); err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	format, err := tdf.ParseFormat(input.Format)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	policyMode, err := tdf.ParsePolicyMode(input.PolicyMode)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	binding, err := tdf.ParseBinding(input.Binding)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	// Get the data to encrypt. Files are streamed into the SDK writer rather
+	// than read into memory, which keeps binaries byte-exact and lets ZTDF
+	// handle inputs far larger than RAM.
+	var reader io.ReadSeeker
+	if input.Input != "" {
+		inFile, err := os.Open(input.Input)
+		if err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to open input file: %v", err)}, nil
+		}
+		defer inFile.Close()
+		reader = inFile
+	} else {
+		// Use literal data
+		reader = strings.NewReader(input.Data)
+	}
+
+	if input.MemoSource != "" && (input.Memo || len(input.Fields) > 0) {
+		return nil, EncryptToolOutput{Success: false, Error: "memoSource encrypts the whole input; memo and fields do not apply"}, nil
+	}
+	if len(input.Fields) > 0 && format == tdf.FormatZTDF {
+		return nil, EncryptToolOutput{Success: false, Error: "field-level encryption embeds nanoTDF values; format ztdf does not apply"}, nil
+	}
+	if input.Memo && (len(input.Attributes) > 0 || format == tdf.FormatZTDF) {
+		return nil, EncryptToolOutput{Success: false, Error: "memo encryption takes its attributes from the portion markings; attributes and format ztdf do not apply"}, nil
+	}
+	table := memotdf.DefaultTable()
+	if input.Markings != "" {
+		if table, err = memotdf.LoadTable(input.Markings); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+	}
+
+	// The attributes of a memo, or a PDF rendered from it, come from its
+	// frontmatter; explicit attributes may only restate them
+	var derivation *memotdf.Derivation
+	if input.MemoSource != "" {
+		if input.Input == "" {
+			return nil, EncryptToolOutput{Success: false, Error: "memoSource requires 'input' to be the memo or its rendered PDF"}, nil
+		}
+		if err := memotdf.CheckRendition(input.Input, input.MemoSource); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		d, err := memotdf.DeriveFile(input.MemoSource, table)
+		if err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		if err := d.Check(input.Attributes); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		input.Attributes = d.Attributes
+		derivation = &d
+	}
+
+	// Normalize the FQNs and confirm they name active values, so a typo
+	// cannot produce a file nobody can decrypt
+	if err := verifyEncryptAttributes(ctx, client, table, &input); err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	// Memo portions take their attributes from the markings table, so the
+	// caller's own access is checked once they are sealed
+	var warnings []string
+	if !input.NoVerify && !input.Memo {
+		attrs := slices.Clone(input.Attributes)
+		for _, f := range input.Fields {
+			attrs = append(attrs, f.Attributes...)
+		}
+		if w := lockoutWarning(ctx, client, input, attrs); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	if len(input.Fields) > 0 {
+		sealer := tdf.NewSealer(client, tdf.KasURL(getPlatformEndpoint()), policyMode, binding)
+		res, output, err := encryptDocument(sealer, reader, table, input)
+		addWarnings(res, &output, warnings)
+		return res, output, err
+	}
+	if input.Memo {
+		sealer := tdf.NewSealer(client, tdf.KasURL(getPlatformEndpoint()), policyMode, binding)
+		return encryptMemo(ctx, client, sealer, reader, table, input)
+	}
+
+	// Refuse to label marked plaintext below its classification before
+	// anything is written
+	override, err := guardMarkings(reader, table, input)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if override != "" {
+		warnings = append(warnings, override)
+	}
+
+	mimeType := input.MimeType
+	if format == tdf.FormatZTDF && mimeType == "" {
+		if mimeType, err = tdf.DetectMimeType(input.Input, reader); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+	}
+
+	opts := tdf.EncryptOptions{
+		Format:     format,
+		Attributes: input.Attributes,
+		KasURL:     tdf.KasURL(getPlatformEndpoint()),
+		MimeType:   mimeType,
+		PolicyMode: policyMode,
+		Binding:    binding,
+	}
+
+	// Without an output path the TDF is returned inline instead of being
+	// written to disk, so nothing in the working directory is overwritten.
+	if input.Output == "" {
+		res, output, err := encryptInline(client, reader, opts)
+		addDerivation(res, &output, derivation)
+		addWarnings(res, &output, warnings)
+		return res, output, err
+	}
+
+	// Encrypt next to the output path and rename once done, so a failure
+	// never truncates or removes a file already there
+	file, err := tdf.CreateOutput(input.Output, 0o644)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer file.Discard()
+
+	result, err := tdf.Encrypt(client, file, reader, opts)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to encrypt: %v", err)}, nil
+	}
+	if err := file.Commit(); err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	msg := fmt.Sprintf("Successfully encrypted %s to %s (%s, %s)", tdf.HumanSize(result.PlaintextSize), input.Output, format, tdf.HumanSize(result.EncryptedSize))
+	res := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}
+	output := EncryptToolOutput{
+		Success:       true,
+		OutputFile:    input.Output,
+		Format:        string(format),
+		PlaintextSize: result.PlaintextSize,
+		EncryptedSize: result.EncryptedSize,
+		Message:       msg,
+	}
+	addDerivation(res, &output, derivation)
+	addWarnings(res, &output, warnings)
+	return res, output, nil
+}
+
+// verifyEncryptAttributes normalizes the attributes and field attributes of
+// input in place and, unless input.NoVerify is set, confirms that they and,
+// for memo encryption, the attributes of the markings table are active
+// values on the platform.
+func verifyEncryptAttributes(ctx context.Context, client *sdk.SDK, table *memotdf.Table, input *EncryptToolInput) error {
+	var err error
+	if input.Attributes, err = tdf.NormalizeAttributes(input.Attributes); err != nil {
+		return err
+	}
+	all := slices.Clone(input.Attributes)
+	for i := range input.Fields {
+		if input.Fields[i].Attributes, err = tdf.NormalizeAttributes(input.Fields[i].Attributes); err != nil {
+			return err
+		}
+		all = append(all, input.Fields[i].Attributes...)
+	}
+	if input.Memo {
+		for _, attrs := range table.Markings {
+			all = append(all, attrs...)
+		}
+	}
+	if input.NoVerify {
+		return nil
+	}
+	if _, err := tdf.VerifyAttributes(ctx, client, all); err != nil {
+		return fmt.Errorf("%w (set noVerify to skip this check offline)", err)
+	}
+	return nil
+}
+
+// guardMarkings checks the classification markings of the plaintext in
+// reader against input.Attributes and rewinds it. When input.OverrideMarkings
+// is set a failed check is logged with the agent identity and returned as
+// the override to report instead of an error.
+func guardMarkings(reader io.ReadSeeker, table *memotdf.Table, input EncryptToolInput) (string, error) {
+	markings, err := memotdf.ScanMarkings(reader)
+	if err != nil {
+		return "", err
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind input: %w", err)
+	}
+	source := input.Input
+	if source == "" {
+		source = "(literal data)"
+	}
+	return checkMarkings(markings, table, input.OverrideMarkings, input.ClientID, source, input.Attributes)
+}
+
+// valueGuard returns a guard for doctdf or csvtdf that checks the markings
+// of each sealed value of source against its own attributes, collecting
+// overrides in overrides.
+func valueGuard(table *memotdf.Table, override bool, clientID, source string, overrides *[]string) func(location string, text []byte, attrs []string) error {
+	if source == "" {
+		source = "(literal data)"
+	}
+	return func(location string, text []byte, attrs []string) error {
+		markings, err := memotdf.ScanMarkings(bytes.NewReader(text))
+		if err != nil {
+			return err
+		}
+		msg, err := checkMarkings(markings, table, override, clientID, source+" "+location, attrs)
+		if msg != "" {
+			*overrides = append(*overrides, location+": "+msg)
+		}
+		return err
+	}
+}
+
+// checkMarkings checks markings found in source against attrs, logging and
+// reporting an override when override is set.
+func checkMarkings(markings []memotdf.Marking, table *memotdf.Table, override bool, clientID, source string, attrs []string) (string, error) {
+	guardErr := table.CheckMarkings(markings, attrs)
+	if guardErr == nil {
+		return "", nil
+	}
+	if !override {
+		return "", fmt.Errorf("%w; add the missing attributes, or set overrideMarkings once a human has confirmed the markings are wrong", guardErr)
+	}
+
+	if clientID == "" {
+		clientID = getClientID()
+	}
+	log.Printf("AUDIT: classification-marking override by %s, client %q, input %s, attributes %v: %v\n",
+		agentIdentity(), clientID, source, attrs, guardErr)
+	return fmt.Sprintf("classification-marking guard overridden: %v", guardErr), nil
+}
+
+// agentIdentity describes the agent holding the OPENTDF_AGENT_JWT token for
+// audit logs.
+func agentIdentity() string {
+	return agent.Identity(getAgentJWT())
+}
+
+// lockoutWarning returns a warning when the identity the server encrypts
+// as would be denied data with attrs, every attribute the output is sealed
+// with, that is, when the caller could not decrypt its own output.
+func lockoutWarning(ctx context.Context, client *sdk.SDK, input EncryptToolInput, attrs []string) string {
+	clientID, clientSecret := input.ClientID, input.ClientSecret
+	if clientID == "" {
+		clientID = getClientID()
+	}
+	if clientSecret == "" {
+		clientSecret = getClientSecret()
+	}
+	// Without credentials there is no identity to check
+	if clientID == "" || clientSecret == "" || len(attrs) == 0 {
+		return ""
+	}
+	attrs = slices.Compact(slices.Sorted(slices.Values(attrs)))
+	allowed, err := access.CanRead(ctx, client, access.Entity{Type: access.TypeClientID, ID: clientID}, attrs)
+	if err != nil {
+		return fmt.Sprintf("could not check the caller's own access to the output: %v", err)
+	}
+	if !allowed {
+		return fmt.Sprintf("client %q is not entitled to %s and will not be able to decrypt the output", clientID, strings.Join(attrs, ", "))
+	}
+	return ""
+}
+
+// addWarnings reports warnings, such as an overridden classification-marking
+// guard, on a successful encrypt result.
+func addWarnings(res *mcp.CallToolResult, output *EncryptToolOutput, warnings []string) {
+	if len(warnings) == 0 || !output.Success {
+		return
+	}
+	output.Warnings = append(output.Warnings, warnings...)
+	output.Message += ". Warning: " + strings.Join(warnings, "; ")
+	if text, ok := res.Content[0].(*mcp.TextContent); ok {
+		text.Text = output.Message
+	}
+}
+
+// addDerivation reports the attributes taken from memo frontmatter on a
+// successful encrypt result.
+func addDerivation(res *mcp.CallToolResult, output *EncryptToolOutput, d *memotdf.Derivation) {
+	if d == nil || !output.Success {
+		return
+	}
+	output.Derivation = d
+	output.Message += fmt.Sprintf(". Attributes from the memo frontmatter (classification %q): %s", d.Classification, strings.Join(d.Attributes, ", "))
+	if text, ok := res.Content[0].(*mcp.TextContent); ok {
+		text.Text = output.Message
+	}
+}
+
+// errInlineLimit is returned by cappedBuffer once the inline size cap is hit.
+var errInlineLimit = errors.New("inline size limit exceeded")
+
+// cappedBuffer collects encrypted output in memory up to limit bytes and
+// fails the write that would exceed it, so oversized TDFs abort early
+// instead of being buffered in full.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int64
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	if int64(c.Len()+len(p)) > c.limit {
+		return 0, errInlineLimit
+	}
+	return c.Buffer.Write(p)
+}
+
+// encryptInline encrypts into memory and returns the TDF base64 encoded in
+// the tool output and as an embedded MCP resource.
+func encryptInline(client *sdk.SDK, reader io.ReadSeeker, opts tdf.EncryptOptions) (*mcp.CallToolResult, EncryptToolOutput, error) {
+	limit := getMaxInlineSize()
+	tooLarge := fmt.Sprintf("encrypted output would exceed the %s inline limit; specify 'output' to write it to a file", tdf.HumanSize(limit))
+
+	// The TDF is always at least as large as the plaintext, so refuse
+	// oversized inputs before doing any work.
+	if size, err := reader.Seek(0, io.SeekEnd); err == nil && size > limit {
+		return nil, EncryptToolOutput{Success: false, Error: tooLarge}, nil
+	}
+
+	buf := &cappedBuffer{limit: limit}
+	result, err := tdf.Encrypt(client, buf, reader, opts)
+	if errors.Is(err, errInlineLimit) {
+		return nil, EncryptToolOutput{Success: false, Error: tooLarge}, nil
+	}
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to encrypt: %v", err)}, nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	msg := fmt.Sprintf("Successfully encrypted %s inline (%s, %s); the base64 TDF is in encryptedData", tdf.HumanSize(result.PlaintextSize), opts.Format, tdf.HumanSize(result.EncryptedSize))
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+			&mcp.EmbeddedResource{
+				Resource: &mcp.ResourceContents{
+					URI:      "opentdf:encrypted" + opts.Format.Extension(),
+					MIMEType: opts.Format.MediaType(),
+					Blob:     buf.Bytes(),
+				},
+			},
+		},
+	}, EncryptToolOutput{
+		Success:       true,
+		EncryptedData: encoded,
+		Format:        string(opts.Format),
+		PlaintextSize: result.PlaintextSize,
+		EncryptedSize: result.EncryptedSize,
+		Message:       msg,
+	}, nil
+}
+
+// openDecryptInput resolves the decrypt tool's input to a seekable reader.
+// An existing file path is opened directly; anything else is treated as an
+// inline base64 nanoTDF or ZTDF payload, e.g. from the encrypt tool's
+// encryptedData or an embedded MCP resource.
+func openDecryptInput(input string) (io.ReadSeeker, func(), error) {
+	_, statErr := os.Stat(input)
+	if statErr == nil {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open input file: %w", err)
+		}
+		return file, func() { file.Close() }, nil
+	}
+
+	data, err := tdf.DecodeBase64TDF(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("input is neither a readable file nor base64 encoded TDF data: %w", statErr)
+	}
+	return bytes.NewReader(data), func() {}, nil
+}
+
+// MCPDecrypt decrypts a TDF or nanoTDF file or inline base64 TDF data
+func MCPDecrypt(ctx context.Context, req *mcp.CallToolRequest, input DecryptToolInput) (*mcp.CallToolResult, DecryptToolOutput, error) {
+	// Sealed values in a JSON or YAML document that share a policy share a
+	// nanoTDF collection header, so storing collection keys saves a KAS
+	// rewrap per value
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret, sdk.WithStoreCollectionHeaders())
+	if err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+	if err := tdf.CheckOutput(input.Input, input.Output); err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	// A memo or document with sealed values may be passed inline as text.
+	// Memo frontmatter parses as YAML, so memos are checked first.
+	if _, statErr := os.Stat(input.Input); statErr != nil {
+		if memotdf.IsSealedMemo([]byte(input.Input)) {
+			return decryptMemo(ctx, client, []byte(input.Input), input)
+		}
+		if doctdf.IsSealedDocument([]byte(input.Input)) {
+			return decryptDocument(ctx, client, []byte(input.Input), input)
+		}
+	}
+
+	file, closeInput, err := openDecryptInput(input.Input)
+	if err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer closeInput()
+
+	// Detect the container format and tell the agent what unsupported
+	// input looks like instead of surfacing an opaque SDK error
+	format, err := tdf.DetectFormat(file)
+	if err != nil {
+		out := DecryptToolOutput{Success: false, Error: err.Error()}
+		var unsupported *tdf.UnsupportedFormatError
+		if errors.As(err, &unsupported) {
+			out.DetectedFormat = unsupported.Detected
+			// A memo with encrypted portions or a document with field-level
+			// encryption is plaintext apart from its sealed values
+			if unsupported.Detected == tdf.DetectedPlaintext {
+				if data, readErr := io.ReadAll(file); readErr == nil {
+					if memotdf.IsSealedMemo(data) {
+						return decryptMemo(ctx, client, data, input)
+					}
+					if doctdf.IsSealedDocument(data) {
+						return decryptDocument(ctx, client, data, input)
+					}
+				}
+			}
+		}
+		return nil, out, nil
+	}
+	result := DecryptToolOutput{Success: true, Format: string(format)}
+	description := format.String()
+
+	// Report how a nanoTDF policy is stored so reviewers can tell whether
+	// attribute values were readable from the header
+	if format == tdf.FormatNano {
+		policy, err := tdf.ReadNanoPolicy(file)
+		if err != nil {
+			return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		result.PolicyMode = policy.Mode
+		result.PolicyBinding = policy.Binding
+		description += ", " + policy.String()
+	}
+
+	// With an output path the plaintext goes straight to disk and only
+	// metadata is returned, keeping sensitive content out of the model's
+	// context unless it was asked for.
+	if input.Output != "" {
+		return decryptToFile(ctx, client, file, format, input, description, result)
+	}
+
+	var output bytes.Buffer
+	if err := tdf.Decrypt(ctx, client, &output, file, format); err != nil {
+		return nil, decryptFailure(ctx, client, file, input, err), nil
+	}
+
+	result.DecryptedData = output.String()
+	result.Size = int64(output.Len())
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Successfully decrypted (%s):\n%s", description, result.DecryptedData)},
+		},
+	}, result, nil
+}
+
+// decryptToFile decrypts into an owner-only file at the output path of
+// input and fills in the path, size and SHA-256 on result without echoing
+// the plaintext.
+func decryptToFile(ctx context.Context, client *sdk.SDK, r io.ReadSeeker, format tdf.Format, input DecryptToolInput, description string, result DecryptToolOutput) (*mcp.CallToolResult, DecryptToolOutput, error) {
+	path := input.Output
+	// The plaintext is renamed into place only once decryption succeeded,
+	// so a failure leaves an existing file at the path untouched
+	out, err := tdf.CreateOutput(path, 0o600)
+	if err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer out.Discard()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	if err := tdf.Decrypt(ctx, client, io.MultiWriter(out, hash, counter), r, format); err != nil {
+		return nil, decryptFailure(ctx, client, r, input, err), nil
+	}
+	if err := out.Commit(); err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	msg := fmt.Sprintf("Successfully decrypted %s to %s (%d bytes, sha256 %s)", description, path, counter.n, digest)
+	result.OutputFile = path
+	result.Size = counter.n
+	result.SHA256 = digest
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}, result, nil
+}
+
+// decryptFailure classifies a failed decrypt so the agent can tell a denial
+// from an outage. A denial names the policy attribute values the caller
+// lacks, e.g. "access denied: missing flight_id/rch2532102", or says why
+// they cannot be named.
+func decryptFailure(ctx context.Context, client *sdk.SDK, r io.ReadSeeker, input DecryptToolInput, err error) DecryptToolOutput {
+	kind := tdf.ClassifyError(err)
+	out := DecryptToolOutput{Success: false, ErrorKind: string(kind)}
+	msg := kind.Description()
+	if kind == tdf.KindDenied {
+		gaps, gapErr := missingAttributes(ctx, client, r, input)
+		out.MissingAttributes = gaps
+		if len(gaps) > 0 {
+			names := make([]string, len(gaps))
+			for i, g := range gaps {
+				names[i] = g.String()
+			}
+			msg += ": missing " + strings.Join(names, "; ")
+		} else if gapErr != nil {
+			msg += " (" + gapErr.Error() + ")"
+		}
+	}
+	out.Error = fmt.Sprintf("%s: %v", msg, err)
+	return out
+}
+
+// missingAttributes compares the policy of the TDF with the entitlements of
+// the client the decrypt ran as. The error says why the gaps cannot be
+// named, e.g. because the nanoTDF policy is encrypted.
+func missingAttributes(ctx context.Context, client *sdk.SDK, r io.ReadSeeker, input DecryptToolInput) ([]access.Gap, error) {
+	clientID := input.ClientID
+	if clientID == "" {
+		clientID = getClientID()
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("no client ID to compare entitlements for")
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("the policy could not be reread: %w", err)
+	}
+	info, err := tdf.Inspect(r)
+	if err != nil {
+		return nil, fmt.Errorf("the policy could not be read: %w", err)
+	}
+	attrs, readable := info.PolicyAttributes()
+	if !readable {
+		return nil, fmt.Errorf("the nanoTDF policy is encrypted, so the missing attributes cannot be named")
+	}
+	if attrs, err = tdf.NormalizeAttributes(attrs); err != nil {
+		return nil, fmt.Errorf("the policy attributes could not be parsed: %w", err)
+	}
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	gaps, err := access.MissingEntitlements(ctx, client, access.Entity{Type: access.TypeClientID, ID: clientID}, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("the entitlements of %q could not be looked up: %w", clientID, err)
+	}
+	return gaps, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// encryptDocument encrypts the selected fields of a JSON or YAML document in
+// place and returns it inline, or writes it to the output path. The markings
+// of each field are checked against the attributes it is sealed with.
+func encryptDocument(sealer *tdf.Sealer, reader io.Reader, table *memotdf.Table, input EncryptToolInput) (*mcp.CallToolResult, EncryptToolOutput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to read input: %v", err)}, nil
+	}
+	syntax := doctdf.DetectSyntax(input.Input, data)
+	var overrides []string
+	doc, result, err := doctdf.Encrypt(sealer, data, syntax, input.Fields, input.Attributes, valueGuard(table, input.OverrideMarkings, input.ClientID, input.Input, &overrides))
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to encrypt: %v", err)}, nil
+	}
+
+	output := EncryptToolOutput{
+		Success:       true,
+		OutputFile:    input.Output,
+		Format:        string(syntax),
+		PlaintextSize: int64(len(data)),
+		EncryptedSize: int64(len(doc)),
+		SealedFields:  result.Sealed,
+	}
+	if input.Output == "" {
+		output.Document = string(doc)
+		output.Message = fmt.Sprintf("Successfully encrypted %d fields of the %s document inline; the document is in document", len(result.Sealed), syntax)
+	} else {
+		if err := tdf.WriteOutput(input.Output, doc, 0o644); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		output.Message = fmt.Sprintf("Successfully encrypted %d fields of the %s document to %s", len(result.Sealed), syntax, input.Output)
+	}
+	if len(result.Unmatched) > 0 {
+		output.Message += fmt.Sprintf(". Selectors that matched nothing: %s", strings.Join(result.Unmatched, ", "))
+	}
+	res := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.Message},
+		},
+	}
+	addWarnings(res, &output, overrides)
+	return res, output, nil
+}
+
+// decryptDocument opens the sealed values of a JSON or YAML document,
+// redacting those the caller may not open, and returns it inline or writes
+// it to an owner-only output file.
+func decryptDocument(ctx context.Context, client *sdk.SDK, data []byte, input DecryptToolInput) (*mcp.CallToolResult, DecryptToolOutput, error) {
+	syntax := doctdf.DetectSyntax(input.Input, data)
+	doc, result, err := doctdf.Decrypt(ctx, tdf.NewOpener(client), data, syntax)
+	if err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	output := DecryptToolOutput{
+		Success:        true,
+		Format:         string(syntax),
+		Size:           int64(len(doc)),
+		OpenedFields:   result.Opened,
+		RedactedFields: result.Redacted,
+	}
+	summary := fmt.Sprintf("%d fields opened, %d redacted", len(result.Opened), len(result.Redacted))
+	return returnDecrypted(doc, fmt.Sprintf("%s document", syntax), summary, input.Output, output)
+}
+
+// returnDecrypted returns a partially decrypted document inline, or writes
+// it to an owner-only output file and returns only the summary.
+func returnDecrypted(doc []byte, description, summary, path string, output DecryptToolOutput) (*mcp.CallToolResult, DecryptToolOutput, error) {
+	if path == "" {
+		output.DecryptedData = string(doc)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Successfully decrypted %s (%s):\n%s", description, summary, output.DecryptedData)},
+			},
+		}, output, nil
+	}
+
+	if err := tdf.WriteOutput(path, doc, 0o600); err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	output.OutputFile = path
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Successfully decrypted %s to %s (%s)", description, path, summary)},
+		},
+	}, output, nil
+}
+
+// encryptMemo encrypts the classified portions of memo markdown and returns
+// the memo inline, or writes it to the output path. Unless input.NoVerify
+// is set it warns when the caller could not open the portions it sealed.
+func encryptMemo(ctx context.Context, client *sdk.SDK, sealer *tdf.Sealer, reader io.Reader, table *memotdf.Table, input EncryptToolInput) (*mcp.CallToolResult, EncryptToolOutput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to read input: %v", err)}, nil
+	}
+	doc, result, err := memotdf.Encrypt(sealer, data, table)
+	if err != nil {
+		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to encrypt: %v", err)}, nil
+	}
+	warnings := result.Warnings
+	if !input.NoVerify {
+		if w := lockoutWarning(ctx, client, input, result.Attributes()); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	output := EncryptToolOutput{
+		Success:        true,
+		OutputFile:     input.Output,
+		Format:         "memo",
+		PlaintextSize:  int64(len(data)),
+		EncryptedSize:  int64(len(doc)),
+		SealedPortions: result.Sealed,
+		Warnings:       warnings,
+	}
+	if input.Output == "" {
+		output.Document = string(doc)
+		output.Message = fmt.Sprintf("Successfully encrypted %d portions of the memo inline (%d left in the clear); the memo is in document", len(result.Sealed), result.Clear)
+	} else {
+		if err := tdf.WriteOutput(input.Output, doc, 0o644); err != nil {
+			return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		output.Message = fmt.Sprintf("Successfully encrypted %d portions of the memo to %s (%d left in the clear)", len(result.Sealed), input.Output, result.Clear)
+	}
+	if len(warnings) > 0 {
+		output.Message += ". Warnings: " + strings.Join(warnings, "; ")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.Message},
+		},
+	}, output, nil
+}
+
+// decryptMemo opens the sealed portions of a memo. Portions the caller may
+// not open keep their marking and read [REDACTED], so a reader cleared for
+// less than the whole memo still gets the rest of it.
+func decryptMemo(ctx context.Context, client *sdk.SDK, data []byte, input DecryptToolInput) (*mcp.CallToolResult, DecryptToolOutput, error) {
+	doc, result, err := memotdf.Decrypt(ctx, tdf.NewOpener(client), data)
+	if err != nil {
+		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	output := DecryptToolOutput{
+		Success:          true,
+		Format:           "memo",
+		Size:             int64(len(doc)),
+		OpenedPortions:   result.Opened,
+		RedactedPortions: result.Redacted,
+	}
+	summary := fmt.Sprintf("%d portions opened, %d redacted", len(result.Opened), len(result.Redacted))
+	return returnDecrypted(doc, "memo", summary, input.Output, output)
+}
+
+// MCPInspect reports the header or manifest of a TDF without decrypting it
+func MCPInspect(ctx context.Context, req *mcp.CallToolRequest, input InspectToolInput) (*mcp.CallToolResult, InspectToolOutput, error) {
+	file, closeInput, err := openDecryptInput(input.Input)
+	if err != nil {
+		return nil, InspectToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer closeInput()
+
+	info, err := tdf.Inspect(file)
+	if err != nil {
+		return nil, InspectToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	infoJSON, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, InspectToolOutput{Success: false, Error: fmt.Sprintf("failed to marshal inspection: %v", err)}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(infoJSON)},
+		},
+	}, InspectToolOutput{Success: true, Inspection: info}, nil
+}
+
+// MCPRelabel changes the attribute policy of an existing TDF without
+// writing its plaintext to disk
+func MCPRelabel(ctx context.Context, req *mcp.CallToolRequest, input RelabelToolInput) (*mcp.CallToolResult, RelabelToolOutput, error) {
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, RelabelToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	file, closeInput, err := openDecryptInput(input.Input)
+	if err != nil {
+		return nil, RelabelToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer closeInput()
+
+	opts := tdf.RelabelOptions{
+		Add:     input.AddAttributes,
+		Remove:  input.RemoveAttributes,
+		Set:     input.SetAttributes,
+		Replace: len(input.SetAttributes) > 0,
+		KasURL:  tdf.KasURL(getPlatformEndpoint()),
+	}
+
+	var out io.Writer
+	var inline *cappedBuffer
+	var tmp *os.File
+	if input.Output == "" {
+		inline = &cappedBuffer{limit: getMaxInlineSize()}
+		out = inline
+	} else {
+		// Write next to the destination and rename, so an input that is
+		// being replaced is only overwritten once the new TDF is complete
+		if tmp, err = os.CreateTemp(filepath.Dir(input.Output), ".tmp-"+filepath.Base(input.Output)+"-*"); err != nil {
+			return nil, RelabelToolOutput{Success: false, Error: fmt.Sprintf("failed to create output file: %v", err)}, nil
+		}
+		defer os.Remove(tmp.Name())
+		out = tmp
+	}
+
+	result, err := tdf.Relabel(ctx, client, out, file, opts)
+	if tmp != nil {
+		if closeErr := tmp.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write output file: %w", closeErr)
+		}
+		if err == nil {
+			if err = os.Chmod(tmp.Name(), 0o644); err == nil {
+				err = os.Rename(tmp.Name(), input.Output)
+			}
+		}
+	}
+	if errors.Is(err, errInlineLimit) {
+		return nil, RelabelToolOutput{Success: false, Error: fmt.Sprintf("relabeled TDF would exceed the %s inline limit; specify 'output' to write it to a file", tdf.HumanSize(getMaxInlineSize()))}, nil
+	}
+	if err != nil {
+		return nil, RelabelToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	var table strings.Builder
+	if err := tdf.WritePolicyDiff(&table, result); err != nil {
+		return nil, RelabelToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	output := RelabelToolOutput{
+		Success:       true,
+		OutputFile:    input.Output,
+		Format:        string(result.Format),
+		OldAttributes: result.OldAttributes,
+		NewAttributes: result.NewAttributes,
+		Changes:       result.Changes,
+	}
+	msg := fmt.Sprintf("Relabeled %s to %s\n\n%s", result.Format, input.Output, table.String())
+	if inline != nil {
+		output.EncryptedData = base64.StdEncoding.EncodeToString(inline.Bytes())
+		msg = fmt.Sprintf("Relabeled %s inline; the base64 TDF is in encryptedData\n\n%s", result.Format, table.String())
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}, output, nil
+}
+
+// MCPConvert re-encapsulates a TDF in the other container format with the
+// same attribute policy
+func MCPConvert(ctx context.Context, req *mcp.CallToolRequest, input ConvertToolInput) (*mcp.CallToolResult, ConvertToolOutput, error) {
+	if input.Format == "" {
+		return nil, ConvertToolOutput{Success: false, Error: "'format' is required"}, nil
+	}
+	opts := tdf.ConvertOptions{
+		Attributes:           input.Attributes,
+		AllowUnverified:      input.AllowUnverified,
+		AllowPlaintextPolicy: input.AllowPlaintextPolicy,
+		KasURL:               tdf.KasURL(getPlatformEndpoint()),
+	}
+	var err error
+	if opts.Target, err = tdf.ParseFormat(input.Format); err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if opts.PolicyMode, err = tdf.ParsePolicyMode(input.PolicyMode); err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if opts.Binding, err = tdf.ParseBinding(input.Binding); err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if input.Output != "" && input.Output == input.Input {
+		return nil, ConvertToolOutput{Success: false, Error: "'output' would overwrite the input"}, nil
+	}
+
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	file, closeInput, err := openDecryptInput(input.Input)
+	if err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer closeInput()
+
+	var out io.Writer
+	var inline *cappedBuffer
+	var tmp *os.File
+	if input.Output == "" {
+		inline = &cappedBuffer{limit: getMaxInlineSize()}
+		out = inline
+	} else {
+		// Write next to the destination and rename, so a failed conversion
+		// never leaves a partial TDF behind
+		if tmp, err = os.CreateTemp(filepath.Dir(input.Output), ".tmp-"+filepath.Base(input.Output)+"-*"); err != nil {
+			return nil, ConvertToolOutput{Success: false, Error: fmt.Sprintf("failed to create output file: %v", err)}, nil
+		}
+		defer os.Remove(tmp.Name())
+		out = tmp
+	}
+
+	result, err := tdf.Convert(ctx, client, out, file, opts)
+	if tmp != nil {
+		if closeErr := tmp.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write output file: %w", closeErr)
+		}
+		if err == nil {
+			if err = os.Chmod(tmp.Name(), 0o644); err == nil {
+				err = os.Rename(tmp.Name(), input.Output)
+			}
+		}
+	}
+	if errors.Is(err, tdf.ErrUnverifiedPolicy) {
+		return nil, ConvertToolOutput{Success: false, Error: fmt.Sprintf("%v; set 'allowUnverified' only if 'attributes' are the complete policy it was encrypted with", err)}, nil
+	}
+	if errors.Is(err, tdf.ErrExposedPolicy) {
+		return nil, ConvertToolOutput{Success: false, Error: fmt.Sprintf("%v; set 'allowPlaintextPolicy' only if its attribute values may be disclosed", err)}, nil
+	}
+	if errors.Is(err, errInlineLimit) {
+		return nil, ConvertToolOutput{Success: false, Error: fmt.Sprintf("converted TDF would exceed the %s inline limit; specify 'output' to write it to a file", tdf.HumanSize(getMaxInlineSize()))}, nil
+	}
+	if err != nil {
+		return nil, ConvertToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	output := ConvertToolOutput{
+		Success:          true,
+		OutputFile:       input.Output,
+		SourceFormat:     string(result.Source),
+		TargetFormat:     string(result.Target),
+		SourceAttributes: result.SourceAttributes,
+		TargetAttributes: result.TargetAttributes,
+		PolicyVerified:   result.PolicyVerified,
+		Warnings:         result.Warnings,
+	}
+	msg := fmt.Sprintf("Converted %s to %s at %s with attributes %s", result.Source, result.Target, input.Output, strings.Join(result.TargetAttributes, ", "))
+	if inline != nil {
+		output.EncryptedData = base64.StdEncoding.EncodeToString(inline.Bytes())
+		msg = fmt.Sprintf("Converted %s to %s inline; the base64 TDF is in encryptedData. Attributes: %s", result.Source, result.Target, strings.Join(result.TargetAttributes, ", "))
+	}
+	for _, w := range result.Warnings {
+		msg += "\nWarning: " + w
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}, output, nil
 }
 
-// JWT Claims structure for agent authentication
-type JWTClaims struct {
-	Sub         string   `json:"sub"`         // Subject (agent ID)
-	Iss         string   `json:"iss"`         // Issuer
-	Aud         string   `json:"aud"`         // Audience
-	Iat         int64    `json:"iat"`         // Issued at
-	Exp         int64    `json:"exp"`         // Expiration
-	AgentName   string   `json:"agent_name"`  // Agent display name
-	Permissions []string `json:"permissions"` // Granted permissions
-}
+// MCPEncryptCSV encrypts the cells or rows of a CSV file according to a
+// rules file, leaving the header and clear columns readable
+func MCPEncryptCSV(ctx context.Context, req *mcp.CallToolRequest, input EncryptCSVToolInput) (*mcp.CallToolResult, EncryptCSVToolOutput, error) {
+	if input.Input == "" || input.Rules == "" {
+		return nil, EncryptCSVToolOutput{Success: false, Error: "'input' and 'rules' are required"}, nil
+	}
+	rules, err := csvtdf.LoadRules(input.Rules)
+	if err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	policyMode, err := tdf.ParsePolicyMode(input.PolicyMode)
+	if err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	binding, err := tdf.ParseBinding(input.Binding)
+	if err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if err := tdf.CheckOutput(input.Input, input.Output); err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	table := memotdf.DefaultTable()
+	if input.Markings != "" {
+		if table, err = memotdf.LoadTable(input.Markings); err != nil {
+			return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+		}
+	}
 
-// parseJWT parses a JWT token and extracts claims (mock implementation - no signature verification)
-func parseJWT(token string) (*JWTClaims, error) {
-	if token == "" {
-		return nil, fmt.Errorf("no JWT token provided")
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
 	}
+	defer client.Close()
 
-	// Split the JWT into parts
-	parts := strings.Split(token, ".")
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid JWT format")
+	// A typo in the rules would otherwise seal cells nobody can open
+	if !input.NoVerify {
+		if _, err := tdf.VerifyAttributes(ctx, client, rules.AttributeValues()); err != nil {
+			return nil, EncryptCSVToolOutput{Success: false, Error: fmt.Sprintf("%v (set noVerify to skip this check offline)", err)}, nil
+		}
 	}
 
-	// Decode the payload (second part)
-	payload := parts[1]
+	file, err := os.Open(input.Input)
+	if err != nil {
+		return nil, EncryptCSVToolOutput{Success: false, Error: fmt.Sprintf("failed to open input file: %v", err)}, nil
+	}
+	defer file.Close()
 
-	// Add padding if needed for base64 decoding
-	if l := len(payload) % 4; l > 0 {
-		payload += strings.Repeat("=", 4-l)
+	// An output file is renamed into place once complete, so a failure
+	// mid-file leaves no truncated CSV
+	var out io.Writer
+	var inline *cappedBuffer
+	var outFile *tdf.OutputFile
+	if input.Output == "" {
+		inline = &cappedBuffer{limit: getMaxInlineSize()}
+		out = inline
+	} else {
+		if outFile, err = tdf.CreateOutput(input.Output, 0o644); err != nil {
+			return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		defer outFile.Discard()
+		out = outFile
 	}
 
-	// Decode base64
-	decoded, err := base64.URLEncoding.DecodeString(payload)
+	var overrides []string
+	sealer := tdf.NewSealer(client, tdf.KasURL(getPlatformEndpoint()), policyMode, binding)
+	result, err := csvtdf.Encrypt(sealer, out, file, rules, valueGuard(table, input.OverrideMarkings, input.ClientID, input.Input, &overrides))
+	if outFile != nil && err == nil {
+		err = outFile.Commit()
+	}
+	if errors.Is(err, errInlineLimit) {
+		return nil, EncryptCSVToolOutput{Success: false, Error: fmt.Sprintf("encrypted CSV would exceed the %s inline limit; specify 'output' to write it to a file", tdf.HumanSize(getMaxInlineSize()))}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode JWT payload: %w", err)
+		return nil, EncryptCSVToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	// Parse JSON
-	var claims JWTClaims
-	if err := json.Unmarshal(decoded, &claims); err != nil {
-		return nil, fmt.Errorf("failed to parse JWT claims: %w", err)
+	output := EncryptCSVToolOutput{Success: true, OutputFile: input.Output, Summary: &result}
+	msg := fmt.Sprintf("Encrypted %d rows of %s in %s mode to %s: %d values under %d policies. Clear columns: %s. Encrypted columns: %s.",
+		result.Rows, input.Input, result.Mode, input.Output, result.SealedValues, result.Policies,
+		strings.Join(result.ClearColumns, ", "), strings.Join(result.EncryptedColumns, ", "))
+	if inline != nil {
+		output.Data = inline.String()
+		msg = fmt.Sprintf("Encrypted %d rows of %s in %s mode: %d values under %d policies. The encrypted CSV is in data.",
+			result.Rows, input.Input, result.Mode, result.SealedValues, result.Policies)
 	}
-
-	return &claims, nil
+	if len(result.UnknownColumns) > 0 {
+		msg += fmt.Sprintf("\nWarning: columns in the rules but not in the file: %s", strings.Join(result.UnknownColumns, ", "))
+	}
+	for _, o := range overrides {
+		msg += "\nWarning: " + o
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}, output, nil
 }
 
-// validateJWT performs basic validation on JWT claims (mock implementation)
-func validateJWT(claims *JWTClaims) error {
-	if claims == nil {
-		return fmt.Errorf("no claims provided")
+// MCPDecryptCSV rebuilds a CSV file written by encrypt_csv, showing cells
+// the caller cannot open as [REDACTED]
+func MCPDecryptCSV(ctx context.Context, req *mcp.CallToolRequest, input DecryptCSVToolInput) (*mcp.CallToolResult, DecryptCSVToolOutput, error) {
+	if input.Input == "" {
+		return nil, DecryptCSVToolOutput{Success: false, Error: "'input' is required"}, nil
 	}
-
-	// Check audience
-	if claims.Aud != "opentdf-mcp" {
-		return fmt.Errorf("invalid audience: expected 'opentdf-mcp', got '%s'", claims.Aud)
+	if err := tdf.CheckOutput(input.Input, input.Output); err != nil {
+		return nil, DecryptCSVToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	// Check expiration
-	now := time.Now().Unix()
-	if claims.Exp > 0 && claims.Exp < now {
-		return fmt.Errorf("token expired")
+	// Cells sharing a policy share a nanoTDF collection header, so storing
+	// collection keys saves a KAS rewrap per cell
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret, sdk.WithStoreCollectionHeaders())
+	if err != nil {
+		return nil, DecryptCSVToolOutput{Success: false, Error: err.Error()}, nil
 	}
+	defer client.Close()
 
-	// Check issued at
-	if claims.Iat > 0 && claims.Iat > now {
-		return fmt.Errorf("token used before issued")
+	file, err := os.Open(input.Input)
+	if err != nil {
+		return nil, DecryptCSVToolOutput{Success: false, Error: fmt.Sprintf("failed to open input file: %v", err)}, nil
 	}
+	defer file.Close()
 
-	return nil
-}
-
-// logAgentAuthentication logs the agent authentication details
-func logAgentAuthentication() {
-	token := getAgentJWT()
-	if token == "" {
-		log.Println("WARNING: No agent JWT token found. Running without agent authentication.")
-		log.Println("In production, this would require OAuth-issued JWT after user consent.")
-		return
+	// The plaintext is renamed into place only once every cell has been
+	// handled, so a failure leaves an existing file at the path untouched
+	var out io.Writer
+	var inline *cappedBuffer
+	var outFile *tdf.OutputFile
+	if input.Output == "" {
+		inline = &cappedBuffer{limit: getMaxInlineSize()}
+		out = inline
+	} else {
+		if outFile, err = tdf.CreateOutput(input.Output, 0o600); err != nil {
+			return nil, DecryptCSVToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		defer outFile.Discard()
+		out = outFile
 	}
 
-	claims, err := parseJWT(token)
+	result, err := csvtdf.Decrypt(ctx, tdf.NewOpener(client), out, file)
+	if outFile != nil && err == nil {
+		err = outFile.Commit()
+	}
+	if errors.Is(err, errInlineLimit) {
+		return nil, DecryptCSVToolOutput{Success: false, Error: fmt.Sprintf("decrypted CSV would exceed the %s inline limit; specify 'output' to write it to a file", tdf.HumanSize(getMaxInlineSize()))}, nil
+	}
 	if err != nil {
-		log.Printf("WARNING: Failed to parse agent JWT: %v\n", err)
-		return
+		return nil, DecryptCSVToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	if err := validateJWT(claims); err != nil {
-		log.Printf("WARNING: JWT validation failed: %v\n", err)
-		return
+	output := DecryptCSVToolOutput{
+		Success:    true,
+		OutputFile: input.Output,
+		Rows:       result.Rows,
+		Opened:     result.Opened,
+		Redacted:   result.Redacted,
 	}
-
-	log.Println("=== Agent Authentication ===")
-	log.Printf("Agent ID: %s\n", claims.Sub)
-	log.Printf("Agent Name: %s\n", claims.AgentName)
-	log.Printf("Issuer: %s\n", claims.Iss)
-	log.Printf("Permissions: %v\n", claims.Permissions)
-	log.Printf("Expires: %s\n", time.Unix(claims.Exp, 0).Format(time.RFC3339))
-	log.Println("NOTE: This is a MOCK JWT for demo purposes.")
-	log.Println("In production, JWT would be issued by OAuth after user consent.")
-	log.Println("===========================")
+	msg := fmt.Sprintf("Decrypted %d rows to %s: %d cells opened, %d redacted", result.Rows, input.Output, result.Opened, result.Redacted)
+	if inline != nil {
+		output.Data = inline.String()
+		msg = fmt.Sprintf("Decrypted %d rows: %d cells opened, %d redacted\n\n%s", result.Rows, result.Opened, result.Redacted, output.Data)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: msg},
+		},
+	}, output, nil
 }
 
-// getSDKClientMCP creates an authenticated OpenTDF SDK client for MCP
-// If clientID and clientSecret are provided, they take precedence over environment variables
-func getSDKClientMCP(clientID, clientSecret string) (*sdk.SDK, error) {
-	platformEndpoint := getPlatformEndpoint()
+// MCPEncryptBatch encrypts a directory tree according to a labels manifest
+func MCPEncryptBatch(ctx context.Context, req *mcp.CallToolRequest, input EncryptBatchToolInput) (*mcp.CallToolResult, EncryptBatchToolOutput, error) {
+	if input.InputDir == "" || input.OutputDir == "" || input.Manifest == "" {
+		return nil, EncryptBatchToolOutput{Success: false, Error: "'inputDir', 'outputDir' and 'manifest' are required"}, nil
+	}
 
-	// Use provided credentials if available, otherwise fall back to config
-	if clientID == "" {
-		clientID = getClientID()
+	manifest, err := batch.LoadManifest(input.Manifest)
+	if err != nil {
+		return nil, EncryptBatchToolOutput{Success: false, Error: err.Error()}, nil
 	}
-	if clientSecret == "" {
-		clientSecret = getClientSecret()
+
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, EncryptBatchToolOutput{Success: false, Error: err.Error()}, nil
 	}
+	defer client.Close()
 
-	var opts []sdk.Option
-	if clientID != "" && clientSecret != "" {
-		opts = append(opts, sdk.WithClientCredentials(clientID, clientSecret, nil))
-	} else {
-		opts = append(opts, sdk.WithInsecurePlaintextConn())
+	// A typo in the manifest would otherwise produce a tree nobody can open
+	if !input.NoVerify {
+		if _, err := tdf.VerifyAttributes(ctx, client, manifest.AttributeValues()); err != nil {
+			return nil, EncryptBatchToolOutput{Success: false, Error: fmt.Sprintf("%v (set noVerify to skip this check offline)", err)}, nil
+		}
 	}
 
-	client, err := sdk.New(platformEndpoint, opts...)
+	results, err := batch.EncryptDir(client, input.InputDir, input.OutputDir, manifest, batch.EncryptOptions{
+		Format: input.Format,
+		KasURL: tdf.KasURL(getPlatformEndpoint()),
+		Force:  input.Force,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create SDK client: %w", err)
+		return nil, EncryptBatchToolOutput{Success: false, Results: results, Error: err.Error()}, nil
 	}
 
-	return client, nil
+	var table strings.Builder
+	if err := batch.WriteTable(&table, results); err != nil {
+		return nil, EncryptBatchToolOutput{Success: false, Results: results, Error: err.Error()}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: table.String()},
+		},
+	}, EncryptBatchToolOutput{Success: !batch.Failed(results), Results: results, Summary: batch.Summary(results)}, nil
 }
 
-// MCPEncrypt encrypts data with the given attributes
-func MCPEncrypt(ctx context.Context, req *mcp.CallToolRequest, input EncryptToolInput) (*mcp.CallToolResult, EncryptToolOutput, error) {
+// MCPDecryptBatch decrypts every TDF in a directory and reports per-file access
+func MCPDecryptBatch(ctx context.Context, req *mcp.CallToolRequest, input DecryptBatchToolInput) (*mcp.CallToolResult, DecryptBatchToolOutput, error) {
+	if input.InputDir == "" {
+		return nil, DecryptBatchToolOutput{Success: false, Error: "'inputDir' is required"}, nil
+	}
+
 	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
 	if err != nil {
-		return nil, EncryptToolOutput{Success: false, Error: err.Error()}, nil
+		return nil, DecryptBatchToolOutput{Success: false, Error: err.Error()}, nil
 	}
 	defer client.Close()
 
-	// Validate input: either Input or Data must be provided, but not both
+	results, err := batch.DecryptDir(ctx, client, input.InputDir, batch.DecryptOptions{
+		OutputDir: input.OutputDir,
+		Workers:   input.Workers,
+	})
+	if err != nil {
+		return nil, DecryptBatchToolOutput{Success: false, Error: err.Error()}, nil
+	}
+
+	var table strings.Builder
+	if err := batch.WriteDecryptTable(&table, results); err != nil {
+		return nil, DecryptBatchToolOutput{Success: false, Results: results, Error: err.Error()}, nil
+	}
+
+	// Denied files are an expected outcome, so the call itself succeeds
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: table.String()},
+		},
+	}, DecryptBatchToolOutput{Success: true, Results: results, Summary: batch.DecryptSummary(results)}, nil
+}
+
+// MCPSuggestAttributes proposes attribute FQNs for plaintext from the
+// signals found in it, with the confidence and evidence of each
+func MCPSuggestAttributes(ctx context.Context, req *mcp.CallToolRequest, input SuggestAttributesToolInput) (*mcp.CallToolResult, SuggestAttributesToolOutput, error) {
 	if input.Input != "" && input.Data != "" {
-		return nil, EncryptToolOutput{Success: false, Error: "cannot specify both 'input' and 'data' parameters"}, nil
+		return nil, SuggestAttributesToolOutput{Success: false, Error: "cannot specify both 'input' and 'data' parameters"}, nil
 	}
 	if input.Input == "" && input.Data == "" {
-		return nil, EncryptToolOutput{Success: false, Error: "must specify either 'input' (file path) or 'data' (literal data)"}, nil
+		return nil, SuggestAttributesToolOutput{Success: false, Error: "must specify either 'input' (file path) or 'data' (literal text)"}, nil
+	}
+
+	rules := suggest.DefaultRules()
+	if input.Rules != "" {
+		var err error
+		if rules, err = suggest.LoadRules(input.Rules); err != nil {
+			return nil, SuggestAttributesToolOutput{Success: false, Error: err.Error()}, nil
+		}
 	}
 
-	// Get the data to encrypt
-	var dataToEncrypt string
+	text := input.Data
 	if input.Input != "" {
-		// Read file contents
-		fileData, err := os.ReadFile(input.Input)
+		data, err := os.ReadFile(input.Input)
 		if err != nil {
-			return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to read input file: %v", err)}, nil
+			return nil, SuggestAttributesToolOutput{Success: false, Error: fmt.Sprintf("failed to read input file: %v", err)}, nil
+		}
+		text = string(data)
+	}
+
+	output := SuggestAttributesToolOutput{Success: true, Suggestions: rules.Scan(text)}
+	if !input.NoVerify && len(output.Suggestions) > 0 {
+		// The suggestions are still useful without the platform, so a
+		// failed lookup leaves them unchecked
+		client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+		if err == nil {
+			defer client.Close()
+			var defined []string
+			if defined, err = tdf.ListAttributeValues(ctx, client); err == nil {
+				suggest.Check(output.Suggestions, defined)
+			}
+		}
+		if err != nil {
+			output.Warning = fmt.Sprintf("suggestions not checked against the platform: %v", err)
 		}
-		dataToEncrypt = string(fileData)
-	} else {
-		// Use literal data
-		dataToEncrypt = input.Data
 	}
 
-	// Always use nanoTDF format
-	outputFile := input.Output
-	if outputFile == "" {
-		outputFile = "encrypted.ntdf"
+	var summary strings.Builder
+	if len(output.Suggestions) == 0 {
+		summary.WriteString("No attribute signals found")
 	}
+	for _, s := range output.Suggestions {
+		summary.WriteString(fmt.Sprintf("%s [%s, %s] %d matches\n", s.Attribute, s.Confidence, s.Status, s.Occurrences))
+		for _, e := range s.Evidence {
+			summary.WriteString(fmt.Sprintf("  line %d, %s: %q\n", e.Line, e.Rule, e.Text))
+		}
+	}
+	if output.Warning != "" {
+		summary.WriteString("\nWarning: " + output.Warning)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary.String()},
+		},
+	}, output, nil
+}
 
-	// Encrypt
-	reader := strings.NewReader(dataToEncrypt)
-	file, err := os.Create(outputFile)
+// MCPPreviewAccess reports which entities could decrypt data encrypted with
+// a candidate attribute set, from one bulk decision request
+func MCPPreviewAccess(ctx context.Context, req *mcp.CallToolRequest, input PreviewAccessToolInput) (*mcp.CallToolResult, PreviewAccessToolOutput, error) {
+	attrs, err := tdf.NormalizeAttributes(input.Attributes)
 	if err != nil {
-		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to create output file: %v", err)}, nil
+		return nil, PreviewAccessToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if len(attrs) == 0 {
+		return nil, PreviewAccessToolOutput{Success: false, Error: "at least one attribute is required"}, nil
+	}
+	entities, err := access.ParseEntities(input.Entities)
+	if err != nil {
+		return nil, PreviewAccessToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if input.Users != "" {
+		users, err := access.LoadUsers(input.Users)
+		if err != nil {
+			return nil, PreviewAccessToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		entities = append(entities, users...)
+	}
+	if len(entities) == 0 {
+		return nil, PreviewAccessToolOutput{Success: false, Error: "must specify 'entities' or a 'users' file"}, nil
 	}
-	defer file.Close()
 
-	baseKasURL := getPlatformEndpoint()
-	if !strings.HasPrefix(baseKasURL, "http://") && !strings.HasPrefix(baseKasURL, "https://") {
-		baseKasURL = "http://" + baseKasURL
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, PreviewAccessToolOutput{Success: false, Error: err.Error()}, nil
 	}
+	defer client.Close()
 
-	// Create nanoTDF config
-	nanoConfig, err := client.NewNanoTDFConfig()
+	matrix, err := access.Decide(ctx, client, entities, []access.Resource{{Name: "candidate", Attributes: attrs}})
 	if err != nil {
-		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to create nanoTDF config: %v", err)}, nil
+		return nil, PreviewAccessToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	if len(input.Attributes) > 0 {
-		if err := nanoConfig.SetAttributes(input.Attributes); err != nil {
-			return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to set attributes: %v", err)}, nil
+	output := PreviewAccessToolOutput{
+		Success:    true,
+		Attributes: attrs,
+		Decisions:  matrix.Decisions(0),
+		Table:      matrix.Markdown(),
+	}
+	var allowed []string
+	for _, d := range output.Decisions {
+		if d.Allowed {
+			allowed = append(allowed, d.Entity.Label())
 		}
 	}
+	summary := fmt.Sprintf("%d of %d entities could decrypt data encrypted with %s", len(allowed), len(entities), strings.Join(attrs, ", "))
+	if len(allowed) > 0 {
+		summary += ": " + strings.Join(allowed, ", ")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary + "\n\n" + output.Table},
+		},
+	}, output, nil
+}
 
-	nanoConfig.EnableECDSAPolicyBinding()
+// MCPAccessMatrix decides read access for every entity on every TDF or
+// attribute set in a single bulk decision request
+func MCPAccessMatrix(ctx context.Context, req *mcp.CallToolRequest, input AccessMatrixToolInput) (*mcp.CallToolResult, AccessMatrixToolOutput, error) {
+	entities, err := access.ParseEntities(input.Entities)
+	if err != nil {
+		return nil, AccessMatrixToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if input.Users != "" {
+		users, err := access.LoadUsers(input.Users)
+		if err != nil {
+			return nil, AccessMatrixToolOutput{Success: false, Error: err.Error()}, nil
+		}
+		entities = append(entities, users...)
+	}
+	if len(entities) == 0 {
+		return nil, AccessMatrixToolOutput{Success: false, Error: "must specify 'entities' or a 'users' file"}, nil
+	}
 
-	if err := nanoConfig.SetKasURL(baseKasURL + "/kas"); err != nil {
-		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to set KAS URL: %v", err)}, nil
+	resources, err := access.FileResources(input.Files)
+	if err != nil {
+		return nil, AccessMatrixToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	for _, res := range input.AttributeSets {
+		if res.Attributes, err = tdf.NormalizeAttributes(res.Attributes); err != nil {
+			return nil, AccessMatrixToolOutput{Success: false, Error: fmt.Sprintf("%s: %v", res.Name, err)}, nil
+		}
+		resources = append(resources, res)
+	}
+	if len(resources) == 0 {
+		return nil, AccessMatrixToolOutput{Success: false, Error: "must specify 'files' or 'attributeSets'"}, nil
 	}
 
-	if _, err := client.CreateNanoTDF(file, reader, *nanoConfig); err != nil {
-		return nil, EncryptToolOutput{Success: false, Error: fmt.Sprintf("failed to encrypt: %v", err)}, nil
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, AccessMatrixToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	matrix, err := access.Decide(ctx, client, entities, resources)
+	if err != nil {
+		return nil, AccessMatrixToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	msg := fmt.Sprintf("Successfully encrypted data to %s", outputFile)
+	table := matrix.Markdown()
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: msg},
+			&mcp.TextContent{Text: table},
 		},
-	}, EncryptToolOutput{Success: true, OutputFile: outputFile, Message: msg}, nil
+	}, AccessMatrixToolOutput{Success: true, Matrix: matrix, Table: table}, nil
 }
 
-// MCPDecrypt decrypts a TDF or nanoTDF file
-func MCPDecrypt(ctx context.Context, req *mcp.CallToolRequest, input DecryptToolInput) (*mcp.CallToolResult, DecryptToolOutput, error) {
-	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+// MCPGetEntitlements lists the attribute values an entity is entitled to and
+// the actions allowed on each
+func MCPGetEntitlements(ctx context.Context, req *mcp.CallToolRequest, input GetEntitlementsToolInput) (*mcp.CallToolResult, GetEntitlementsToolOutput, error) {
+	ent, err := entitlementsEntity(input)
 	if err != nil {
-		return nil, DecryptToolOutput{Success: false, Error: err.Error()}, nil
+		return nil, GetEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
 	}
-	defer client.Close()
 
-	file, err := os.Open(input.Input)
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
 	if err != nil {
-		return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to open input file: %v", err)}, nil
+		return nil, GetEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
 	}
-	defer file.Close()
+	defer client.Close()
 
-	// Detect format by reading magic bytes
-	var magic [3]byte
-	var isNano bool
-	n, err := io.ReadFull(file, magic[:])
-	switch {
-	case err != nil:
-		return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to read magic bytes: %v", err)}, nil
-	case n < 3:
-		return nil, DecryptToolOutput{Success: false, Error: "file too small; no magic number found"}, nil
-	case bytes.HasPrefix(magic[:], []byte("L1L")):
-		isNano = true
-	default:
-		isNano = false
+	entitlements, err := access.GetEntitlements(ctx, client, ent, input.ComprehensiveHierarchy)
+	if err != nil {
+		return nil, GetEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	// Reset file position
-	if _, err := file.Seek(0, 0); err != nil {
-		return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to seek to beginning: %v", err)}, nil
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("%s is entitled to %d attribute values", ent, len(entitlements)))
+	for _, e := range entitlements {
+		summary.WriteString(fmt.Sprintf("\n%s: %s", e.Attribute, strings.Join(e.Actions, ", ")))
 	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary.String()},
+		},
+	}, GetEntitlementsToolOutput{Success: true, Entity: &ent, Entitlements: entitlements}, nil
+}
 
-	var output bytes.Buffer
-
-	if isNano {
-		if _, err := client.ReadNanoTDF(&output, file); err != nil {
-			return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to decrypt nanoTDF: %v", err)}, nil
+// entitlementsEntity returns the entity a get_entitlements call names, or
+// without an identifier the client this server authenticates as.
+func entitlementsEntity(input GetEntitlementsToolInput) (access.Entity, error) {
+	if input.Identifier == "" {
+		clientID := input.ClientID
+		if clientID == "" {
+			clientID = getClientID()
 		}
-	} else {
-		tdfReader, err := client.LoadTDF(file)
-		if err != nil {
-			return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to load TDF: %v", err)}, nil
+		if clientID == "" {
+			return access.Entity{}, fmt.Errorf("no identifier given and the server has no client credentials to look up")
 		}
-		if _, err := io.Copy(&output, tdfReader); err != nil && err != io.EOF {
-			return nil, DecryptToolOutput{Success: false, Error: fmt.Sprintf("failed to decrypt TDF: %v", err)}, nil
+		return access.Entity{Type: access.TypeClientID, ID: clientID}, nil
+	}
+	typ := input.Type
+	if typ == "" {
+		typ = access.TypeEmail
+	}
+	return access.ParseEntity(typ + ":" + input.Identifier)
+}
+
+// MCPCompareEntitlements compares the entitlements of two or more entities
+// and, given TDFs, the documents each can open that another cannot
+func MCPCompareEntitlements(ctx context.Context, req *mcp.CallToolRequest, input CompareEntitlementsToolInput) (*mcp.CallToolResult, CompareEntitlementsToolOutput, error) {
+	entities, err := access.ParseEntities(input.Entities)
+	if err != nil {
+		return nil, CompareEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	if len(entities) < 2 {
+		return nil, CompareEntitlementsToolOutput{Success: false, Error: "must specify at least two 'entities'"}, nil
+	}
+	documents, err := access.FileResources(input.Files)
+	if err != nil {
+		return nil, CompareEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	for _, res := range input.AttributeSets {
+		if res.Attributes, err = tdf.NormalizeAttributes(res.Attributes); err != nil {
+			return nil, CompareEntitlementsToolOutput{Success: false, Error: fmt.Sprintf("%s: %v", res.Name, err)}, nil
 		}
+		documents = append(documents, res)
+	}
+
+	client, err := getSDKClientMCP(input.ClientID, input.ClientSecret)
+	if err != nil {
+		return nil, CompareEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
+	}
+	defer client.Close()
+
+	comparison, err := access.Compare(ctx, client, entities, documents, input.ComprehensiveHierarchy)
+	if err != nil {
+		return nil, CompareEntitlementsToolOutput{Success: false, Error: err.Error()}, nil
 	}
 
-	decryptedData := output.String()
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Successfully decrypted:\n%s", decryptedData)},
+			&mcp.TextContent{Text: comparison.Summary()},
 		},
-	}, DecryptToolOutput{Success: true, DecryptedData: decryptedData}, nil
+	}, CompareEntitlementsToolOutput{Success: true, Comparison: comparison}, nil
 }
 
 // MCPListAttributes lists available attributes
@@ -433,21 +2023,92 @@ func runMCPServer() error {
 	// Add encrypt tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "encrypt",
-		Description: "Encrypt data using OpenTDF with the specified attributes. Creates a nanoTDF file (.ntdf). Specify either 'input' (file path) or 'data' (literal text).",
+		Description: "Encrypt data using OpenTDF with the specified attributes. Creates a nanoTDF (.ntdf) by default, or a ZTDF (.tdf) with a full manifest when format is 'ztdf'. Specify either 'input' (file path) or 'data' (literal text). Without 'output' the TDF is returned base64 encoded instead of being written to disk. With 'fields' (JSONPath selectors, each with attribute FQNs) only the selected values of a JSON or YAML document are encrypted, as inline nanoTDF strings, so the document stays valid and its structure readable. With 'memo' the input is USAF memo markdown: each portion marked (S), (TS), etc. is encrypted under the matching classification attribute, while (U) portions and the frontmatter stay readable, producing a single markdown file. With 'memoSource' (memo markdown) the attributes are derived from its frontmatter classification, flight identifiers and functional tags, for encrypting the memo or the PDF rendered from it; conflicting explicit 'attributes' are refused. Whole inputs, and with 'fields' each selected value, whose classification banners or portion markings (e.g. TOP SECRET//, (S)) call for a classification their attributes do not include are refused before anything is written; 'overrideMarkings' bypasses this and is audit-logged with the agent identity. Attribute FQNs must name existing, active attribute values; unknown ones fail with 'did you mean' suggestions unless 'noVerify' is set. A warning is returned when the calling identity would not be able to decrypt its own output.",
 	}, MCPEncrypt)
 
 	// Add decrypt tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "decrypt",
-		Description: "Decrypt a TDF or nanoTDF file and return the plaintext data. Automatically detects the format.",
+		Description: "Decrypt a TDF or nanoTDF and return the plaintext data. 'input' may be a file path or base64 encoded TDF data (standard or URL-safe alphabet). Automatically detects the format. With 'output' the plaintext is written to that file (owner-only permissions) and only its size and SHA-256 are returned. A JSON or YAML document with field-level encryption (from encrypt with 'fields') is walked and every value the caller is entitled to is opened; the rest read [REDACTED]. Likewise a memo with encrypted portions (from encrypt with 'memo') is reassembled with every portion the caller may not open replaced by its marking and [REDACTED]. A failure is classified in 'errorKind' (denied, auth_error, network_error, malformed, integrity_error); a denial lists in 'missingAttributes' the policy attribute values the caller is not entitled to, or says in 'error' why they cannot be named (a nanoTDF with an encrypted policy hides its attributes).",
 	}, MCPDecrypt)
 
+	// Add inspect tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "inspect_tdf",
+		Description: "Inspect a TDF without decrypting it or contacting KAS. For nanoTDF returns the header: KAS URL, ECC mode, cipher, policy type, binding type and, for plaintext policies, the data attributes. For ZTDF returns the manifest: key access objects, policy and attributes, assertions, segment sizes and MIME type. 'input' may be a file path or base64 encoded TDF data.",
+	}, MCPInspect)
+
+	// Add relabel tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "relabel",
+		Description: "Change the attribute policy of an existing nanoTDF or ZTDF, e.g. when a document's classification or flight scope changes. Decrypts and re-encrypts in memory (plaintext never touches disk), keeps the original format, KAS, policy mode and MIME type, and reports the old and new policies side by side. Use addAttributes/removeAttributes on ZTDF and plaintext-policy nanoTDFs; a nanoTDF with an encrypted policy (the default, e.g. everything in encrypted-scenario) cannot be read, so give its complete new policy with setAttributes.",
+	}, MCPRelabel)
+
+	// Add convert tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "convert",
+		Description: "Convert a TDF between nanoTDF and ZTDF, e.g. to share a compact nanoTDF with a ZTDF-only consumer. Keeps the attribute policy and KAS; refuses any target policy that drops a source attribute. 'input' may be a file path or base64 encoded TDF data. A source nanoTDF with an encrypted policy cannot be read or checked: it is only converted with 'attributes' and 'allowUnverified', which confirms they are its complete policy, and to ZTDF, whose manifest stores the policy unencrypted, only with 'allowPlaintextPolicy'. Warns about anything the target format cannot carry (ZTDF MIME type, metadata, assertions).",
+	}, MCPConvert)
+
+	// Add CSV tools
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "encrypt_csv",
+		Description: "Encrypt a CSV file cell by cell or row by row, e.g. to share KC-46 flight logs while protecting columns such as System_Error_Code and Pilot_Action. A rules file lists the columns kept in the clear (the header always is) and the attributes for the rest, keyed on column names or on cell values (globs). Each protected cell or row becomes an inline 'ntdf:' base64 nanoTDF. The rule attribute FQNs must name active values unless 'noVerify' is set, and a value whose classification markings call for attributes it does not get is refused unless 'overrideMarkings' is set.",
+	}, MCPEncryptCSV)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "decrypt_csv",
+		Description: "Decrypt a CSV file written by encrypt_csv and rebuild the table. Cells the caller is not entitled to are shown as [REDACTED] instead of failing the whole file; the result reports how many cells were opened and redacted.",
+	}, MCPDecryptCSV)
+
+	// Add encrypt batch tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "encrypt_batch",
+		Description: "Encrypt every file in a directory using a labels manifest that maps file globs (e.g. 'kc-46-*') to attribute FQNs. Mirrors the tree into the output directory, skips files whose plaintext and attributes are unchanged, and returns a per-file result table. Every manifest attribute is checked against the platform first (noVerify skips this offline). A manifest 'policy: plaintext' keeps nanoTDF policies readable for access_matrix and compare_entitlements.",
+	}, MCPEncryptBatch)
+
+	// Add decrypt batch tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "decrypt_batch",
+		Description: "Attempt to decrypt every TDF in a directory with the caller's credentials and report per file whether access was allowed, denied, the file was malformed, or the platform was unreachable. One failure never stops the rest. With 'outputDir' the allowed files are written there; otherwise nothing is written.",
+	}, MCPDecryptBatch)
+
 	// Add list attributes tool
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_attributes",
 		Description: "List available data attributes from the OpenTDF platform. Use verbose mode to see attribute values. Filter by namespace if needed.",
 	}, MCPListAttributes)
 
+	// Add suggest attributes tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "suggest_attributes",
+		Description: "Propose attribute FQNs for plaintext before encrypting it, instead of guessing. Scans a file ('input') or text ('data') for known signals: flight identifiers (RCH followed by 7 digits), tail numbers, classification banners and portion markings, and maintenance vocabulary such as RED-TAGGED or Inspection_ID. Each suggestion has a confidence (high, medium, low), the evidence found with line numbers, and a status saying whether the platform defines the attribute value (from ListAttributes). 'rules' loads custom signals from a YAML or JSON file.",
+	}, MCPSuggestAttributes)
+
+	// Add preview access tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "preview_access",
+		Description: "Show who will be able to decrypt data before encrypting it. Takes a candidate set of attribute FQNs and the entities to check: client IDs, 'email:' or 'username:' identifiers in 'entities', and/or a 'users' file such as masterprompt/users.yaml whose client_ids are the scenario personas. One bulk request to the authorization service decides read access for every entity; returns an allow/deny list and a markdown table.",
+	}, MCPPreviewAccess)
+
+	// Add get entitlements tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_entitlements",
+		Description: "List what an entity is entitled to: a flat list of attribute value FQNs, each with the actions allowed on data carrying it (e.g. read). Look up a user by 'identifier' with 'type' email (default), username or clientId; without an identifier, the client credentials this server is using are looked up. Use it to explain what a user can open, or why a decrypt is denied.",
+	}, MCPGetEntitlements)
+
+	// Add access matrix tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "access_matrix",
+		Description: "Run the Document/User access matrix through the platform's bulk decision API (GetDecisionBulk): one request decides whether each entity may read each TDF. Columns are 'entities' (client IDs, 'email:' or 'username:' identifiers) and/or the client_ids of a 'users' file such as masterprompt/users.yaml; rows are TDF 'files' or directories, and/or named 'attributeSets'. Each file's attributes are read from its policy, so a nanoTDF whose policy is encrypted is refused: rebuild encrypted-scenario with encrypt_batch and scenario-labels.yaml (policy: plaintext) before passing it, or give attribute sets. Returns the grid as JSON and as a markdown table with ✅ (allowed) and ❌ (denied), ready to show.",
+	}, MCPAccessMatrix)
+
+	// Add compare entitlements tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "compare_entitlements",
+		Description: "Compare the entitlements of two or more entities (client IDs, 'email:' or 'username:' identifiers), e.g. julie.lee and sarah.chen: returns the attribute values they all share and the values that set each apart. With 'files' (TDFs or directories) or 'attributeSets' it also lists the documents each entity can open that another cannot, decided in one GetDecisionBulk request. A nanoTDF's attributes are read from its policy, so one whose policy is encrypted is refused: rebuild encrypted-scenario with encrypt_batch and scenario-labels.yaml (policy: plaintext) before passing it, or give attribute sets.",
+	}, MCPCompareEntitlements)
+
 	// Run server over stdio
 	log.Println("Starting OpenTDF MCP server on stdio...")
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {